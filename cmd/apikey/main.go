@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dwarvesf/icy-backend/internal/auth"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/store"
+	pgstore "github.com/dwarvesf/icy-backend/internal/store/postgres"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+// creates an API key and prints it, the key is only shown once
+func main() {
+	name := flag.String("name", "", "name of the API key owner, e.g frontend")
+	role := flag.String("role", string(model.ApiKeyRoleReadOnly), "role of the API key: read-only, operator or admin")
+	flag.Parse()
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "-name is required")
+		os.Exit(1)
+	}
+
+	appConfig, err := config.New()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger := logger.New(appConfig.Environment)
+	pg := pgstore.New(appConfig, logger)
+	defer pg.Close()
+
+	a := auth.New(appConfig, logger, pg.DB(), store.New())
+	rawKey, apiKey, err := a.CreateApiKey(context.Background(), *name, model.ApiKeyRole(*role))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("created API key #%d %q with role %s\n%s\n", apiKey.ID, apiKey.Name, apiKey.Role, rawKey)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultEndpoints = "/api/v1/oracle/circulated-icy,/api/v1/oracle/circulated-icy-cached,/api/v1/oracle/treasury-btc,/api/v1/oracle/treasury-btc-cached,/api/v1/oracle/icy-btc-ratio,/api/v1/oracle/icy-btc-ratio-cached,/api/v1/fees"
+
+type endpointResult struct {
+	Endpoint  string  `json:"endpoint"`
+	Requests  int     `json:"requests"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	P50Ms     float64 `json:"p50_ms"`
+	P90Ms     float64 `json:"p90_ms"`
+	P99Ms     float64 `json:"p99_ms"`
+	MaxMs     float64 `json:"max_ms"`
+}
+
+type report struct {
+	Target      string           `json:"target"`
+	RPS         int              `json:"rps"`
+	Duration    string           `json:"duration"`
+	StartedAt   time.Time        `json:"started_at"`
+	FinishedAt  time.Time        `json:"finished_at"`
+	TotalErrors int              `json:"total_errors"`
+	Endpoints   []endpointResult `json:"endpoints"`
+}
+
+type sample struct {
+	latency time.Duration
+	failed  bool
+}
+
+func main() {
+	target := flag.String("target", "http://localhost:8080", "base URL of the environment under test")
+	rps := flag.Int("rps", 10, "requests per second across all endpoints")
+	duration := flag.Duration("duration", 30*time.Second, "how long to generate traffic")
+	concurrency := flag.Int("concurrency", 50, "max in-flight requests")
+	timeout := flag.Duration("timeout", 10*time.Second, "per request timeout")
+	endpoints := flag.String("endpoints", defaultEndpoints, "comma separated endpoint paths, requested round-robin")
+	out := flag.String("out", "", "write the JSON report to this file instead of stdout")
+	flag.Parse()
+
+	if *rps <= 0 || *concurrency <= 0 {
+		fmt.Fprintln(os.Stderr, "rps and concurrency must be positive")
+		os.Exit(1)
+	}
+	// the ticker interval is truncated to a nanosecond, NewTicker panics on 0
+	if time.Second/time.Duration(*rps) <= 0 {
+		fmt.Fprintf(os.Stderr, "rps must be at most %d\n", int64(time.Second))
+		os.Exit(1)
+	}
+
+	paths := strings.Split(*endpoints, ",")
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// keep a connection per in-flight request alive, so latencies don't include reconnects
+	transport.MaxIdleConnsPerHost = *concurrency
+	client := &http.Client{Timeout: *timeout, Transport: transport}
+
+	var (
+		mux     sync.Mutex
+		wg      sync.WaitGroup
+		samples = map[string][]sample{}
+		slots   = make(chan struct{}, *concurrency)
+	)
+
+	startedAt := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(*rps))
+	defer ticker.Stop()
+
+	for i := 0; time.Since(startedAt) < *duration; i++ {
+		<-ticker.C
+		path := paths[i%len(paths)]
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			s := doRequest(client, *target+path)
+
+			mux.Lock()
+			samples[path] = append(samples[path], s)
+			mux.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	r := report{
+		Target:     *target,
+		RPS:        *rps,
+		Duration:   duration.String(),
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	}
+	for _, path := range paths {
+		result := summarize(path, samples[path])
+		r.TotalErrors += result.Errors
+		r.Endpoints = append(r.Endpoints, result)
+	}
+
+	output, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		fmt.Println(string(output))
+		return
+	}
+	if err := os.WriteFile(*out, output, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func doRequest(client *http.Client, url string) sample {
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return sample{latency: time.Since(start), failed: true}
+	}
+	defer resp.Body.Close()
+
+	// read the body to the end so the connection is reused
+	_, err = io.Copy(io.Discard, resp.Body)
+	latency := time.Since(start)
+
+	return sample{latency: latency, failed: err != nil || resp.StatusCode >= http.StatusBadRequest}
+}
+
+func summarize(endpoint string, samples []sample) endpointResult {
+	result := endpointResult{Endpoint: endpoint, Requests: len(samples)}
+	if len(samples) == 0 {
+		return result
+	}
+
+	latencies := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		if s.failed {
+			result.Errors++
+		}
+		latencies = append(latencies, float64(s.latency.Microseconds())/1000)
+	}
+	sort.Float64s(latencies)
+
+	result.ErrorRate = float64(result.Errors) / float64(len(samples))
+	result.P50Ms = percentile(latencies, 50)
+	result.P90Ms = percentile(latencies, 90)
+	result.P99Ms = percentile(latencies, 99)
+	result.MaxMs = latencies[len(latencies)-1]
+
+	return result
+}
+
+// percentile expects sorted values
+func percentile(sorted []float64, p int) float64 {
+	index := (len(sorted)*p+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+
+	return sorted[index]
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dwarvesf/icy-backend/internal/openapi"
+)
+
+// generates the public OpenAPI 3 spec and the TypeScript client from the
+// handler annotations
+func main() {
+	root := flag.String("root", ".", "repository root")
+	flag.Parse()
+
+	doc, err := openapi.Generate(*root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	spec, err := doc.Marshal()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for file, content := range map[string][]byte{
+		openapi.SpecFile:   spec,
+		openapi.ClientFile: openapi.TypeScriptClient(doc),
+	} {
+		path := filepath.Join(*root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("generated", file)
+	}
+}
@@ -4,6 +4,14 @@ import (
 	"github.com/dwarvesf/icy-backend/internal/server"
 )
 
+// @title ICY Backend API
+// @version 1.0
+// @description Oracle, reserve attestation and operations API for ICY.
+// @BasePath /
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	server.Init()
 }
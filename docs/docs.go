@@ -1,35 +1,22 @@
-// Code generated by swaggo/swag. DO NOT EDIT.
-
+// Package docs serves the generated OpenAPI spec, admin routes included, at
+// /swagger. Run `make gen-openapi` after changing the handler annotations
 package docs
 
-import "github.com/swaggo/swag"
+import (
+	_ "embed"
+
+	"github.com/swaggo/swag"
+)
+
+//go:embed openapi.json
+var spec string
 
-const docTemplate = `{
-    "schemes": {{ marshal .Schemes }},
-    "swagger": "2.0",
-    "info": {
-        "description": "{{escape .Description}}",
-        "title": "{{.Title}}",
-        "contact": {},
-        "version": "{{.Version}}"
-    },
-    "host": "{{.Host}}",
-    "basePath": "{{.BasePath}}",
-    "paths": {}
-}`
+type doc struct{}
 
-// SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo = &swag.Spec{
-	Version:          "",
-	Host:             "",
-	BasePath:         "",
-	Schemes:          []string{},
-	Title:            "",
-	Description:      "",
-	InfoInstanceName: "swagger",
-	SwaggerTemplate:  docTemplate,
+func (doc) ReadDoc() string {
+	return spec
 }
 
 func init() {
-	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+	swag.Register(swag.Name, doc{})
 }
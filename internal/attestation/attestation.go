@@ -0,0 +1,133 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/btcrpc"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+const verificationInstructions = "payload is the JSON encoded snapshot. " +
+	"Verify signature (hex) over the payload bytes with ed25519 using public_key (hex), " +
+	"then compare btc_balance with the balance of btc_treasury_address at btc_block."
+
+var (
+	ErrSigningKeyNotConfigured = errors.New("attestation signing key is not configured")
+	ErrTreasuryNotConfigured   = errors.New("BTC treasury address is not configured")
+	ErrBlockUnavailable        = errors.New("latest BTC block is unavailable")
+	ErrBalanceUnavailable      = errors.New("BTC treasury balance is unavailable")
+	ErrBlockChanged            = errors.New("BTC tip moved while reading the treasury balance")
+)
+
+type Attestation struct {
+	privateKey ed25519.PrivateKey
+
+	appConfig *config.AppConfig
+	logger    *logger.Logger
+	btcRpc    btcrpc.IBtcRpc
+}
+
+func New(appConfig *config.AppConfig, logger *logger.Logger, btcRpc btcrpc.IBtcRpc) IAttestation {
+	a := &Attestation{
+		appConfig: appConfig,
+		logger:    logger,
+		btcRpc:    btcRpc,
+	}
+
+	if appConfig.Attestation.SigningKey != "" {
+		seed, err := hex.DecodeString(appConfig.Attestation.SigningKey)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			logger.Fatal("invalid attestation signing key, expected a hex encoded ed25519 seed")
+		}
+		a.privateKey = ed25519.NewKeyFromSeed(seed)
+	}
+
+	return a
+}
+
+func (a *Attestation) AttestReserves() (*model.ReserveAttestation, error) {
+	if a.privateKey == nil {
+		return nil, ErrSigningKeyNotConfigured
+	}
+
+	treasuryAddress := a.appConfig.Bitcoin.TreasuryAddress
+	if treasuryAddress == "" {
+		return nil, ErrTreasuryNotConfigured
+	}
+
+	btcBlock, btcBalance, err := a.treasuryBalanceAtTip(treasuryAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// circulated ICY is left out until it is read on-chain at a pinned block,
+	// like the treasury balance. The oracle only serves a placeholder for now
+	snapshot := model.ReserveSnapshot{
+		BtcTreasuryAddress: treasuryAddress,
+		BtcBalance:         btcBalance,
+		BtcBlock:           btcBlock,
+		Timestamp:          time.Now().UTC(),
+	}
+
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := ed25519.Sign(a.privateKey, payload)
+
+	return &model.ReserveAttestation{
+		Snapshot:     snapshot,
+		Payload:      string(payload),
+		Signature:    hex.EncodeToString(signature),
+		PublicKey:    hex.EncodeToString(a.privateKey.Public().(ed25519.PublicKey)),
+		Algorithm:    "ed25519",
+		Verification: verificationInstructions,
+	}, nil
+}
+
+// treasuryBalanceAtTip reads the treasury balance between two reads of the
+// chain tip, so the signed balance is anchored to the returned block. It never
+// returns a missing block or balance, the snapshot must not be signed without them
+func (a *Attestation) treasuryBalanceAtTip(treasuryAddress string) (*model.BtcBlock, *model.Web3BigInt, error) {
+	before, err := a.latestBlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	balance, err := a.btcRpc.BalanceOf(treasuryAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if balance == nil || balance.Value == "" {
+		return nil, nil, ErrBalanceUnavailable
+	}
+
+	after, err := a.latestBlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	if after.Hash != before.Hash {
+		return nil, nil, ErrBlockChanged
+	}
+
+	return before, balance, nil
+}
+
+func (a *Attestation) latestBlock() (*model.BtcBlock, error) {
+	block, err := a.btcRpc.GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if block == nil || block.Height == 0 || block.Hash == "" {
+		return nil, ErrBlockUnavailable
+	}
+
+	return block, nil
+}
@@ -0,0 +1,96 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/btcrpc"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+type fakeBtcRpc struct {
+	btcrpc.IBtcRpc
+	blocks  []*model.BtcBlock
+	balance *model.Web3BigInt
+}
+
+func (f *fakeBtcRpc) BalanceOf(string) (*model.Web3BigInt, error) {
+	return f.balance, nil
+}
+
+func (f *fakeBtcRpc) GetLatestBlock() (*model.BtcBlock, error) {
+	block := f.blocks[0]
+	if len(f.blocks) > 1 {
+		f.blocks = f.blocks[1:]
+	}
+	return block, nil
+}
+
+var _ = Describe("Attestation", func() {
+	var (
+		appConfig *config.AppConfig
+		log       *logger.Logger
+	)
+
+	BeforeEach(func() {
+		appConfig = &config.AppConfig{
+			Bitcoin:     config.BitcoinConfig{TreasuryAddress: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+			Attestation: config.AttestationConfig{SigningKey: strings.Repeat("01", ed25519.SeedSize)},
+		}
+		log = logger.New(environments.Test)
+	})
+
+	newAttestation := func(btcRpc btcrpc.IBtcRpc) IAttestation {
+		return New(appConfig, log, btcRpc)
+	}
+
+	Describe("#AttestReserves", func() {
+		It("should refuse to attest without a real block", func() {
+			_, err := newAttestation(btcrpc.New(appConfig, log)).AttestReserves()
+			Expect(err).To(MatchError(ErrBlockUnavailable))
+		})
+
+		It("should refuse to attest without a treasury balance", func() {
+			btcRpc := &fakeBtcRpc{blocks: []*model.BtcBlock{{Height: 1, Hash: "00"}}}
+
+			_, err := newAttestation(btcRpc).AttestReserves()
+			Expect(err).To(MatchError(ErrBalanceUnavailable))
+		})
+
+		It("should refuse to attest when the tip moves during the balance read", func() {
+			btcRpc := &fakeBtcRpc{
+				blocks:  []*model.BtcBlock{{Height: 1, Hash: "00"}, {Height: 2, Hash: "01"}},
+				balance: &model.Web3BigInt{Value: "100000000", Decimal: 8},
+			}
+
+			_, err := newAttestation(btcRpc).AttestReserves()
+			Expect(err).To(MatchError(ErrBlockChanged))
+		})
+
+		It("should sign the treasury balance anchored to the block", func() {
+			btcRpc := &fakeBtcRpc{
+				blocks:  []*model.BtcBlock{{Height: 1, Hash: "00"}},
+				balance: &model.Web3BigInt{Value: "100000000", Decimal: 8},
+			}
+
+			attestation, err := newAttestation(btcRpc).AttestReserves()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attestation.Snapshot.BtcBalance.Value).To(Equal("100000000"))
+			Expect(attestation.Snapshot.BtcBlock).To(Equal(&model.BtcBlock{Height: 1, Hash: "00"}))
+			Expect(attestation.Payload).To(ContainSubstring(`"btc_block":{"height":1,"hash":"00"}`))
+			// circulated ICY is not read on-chain yet, it must not be signed
+			Expect(attestation.Payload).NotTo(ContainSubstring("icy_circulated"))
+
+			publicKey, _ := hex.DecodeString(attestation.PublicKey)
+			signature, _ := hex.DecodeString(attestation.Signature)
+			Expect(ed25519.Verify(publicKey, []byte(attestation.Payload), signature)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,9 @@
+package attestation
+
+import "github.com/dwarvesf/icy-backend/internal/model"
+
+type IAttestation interface {
+	// AttestReserves returns a snapshot of the treasury BTC balance and the
+	// circulated ICY, signed by the server attestation key
+	AttestReserves() (*model.ReserveAttestation, error)
+}
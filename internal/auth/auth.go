@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/store"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+const apiKeyPrefix = "icy_"
+
+var ErrInvalidApiKey = errors.New("invalid or revoked API key")
+
+type Auth struct {
+	db        *gorm.DB
+	store     *store.Store
+	appConfig *config.AppConfig
+	logger    *logger.Logger
+}
+
+func New(appConfig *config.AppConfig, logger *logger.Logger, db *gorm.DB, store *store.Store) IAuth {
+	return &Auth{
+		db:        db,
+		store:     store,
+		appConfig: appConfig,
+		logger:    logger,
+	}
+}
+
+func (a *Auth) Authenticate(ctx context.Context, rawKey string) (*model.ApiKey, error) {
+	apiKey, err := a.store.ApiKey.GetByKeyHash(ctx, a.db, hashApiKey(rawKey))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidApiKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.RevokedAt != nil {
+		return nil, ErrInvalidApiKey
+	}
+
+	return apiKey, nil
+}
+
+func (a *Auth) CreateApiKey(ctx context.Context, name string, role model.ApiKeyRole) (string, *model.ApiKey, error) {
+	if !role.IsValid() {
+		return "", nil, fmt.Errorf("invalid role %q", role)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(secret)
+
+	apiKey := &model.ApiKey{
+		Name:    name,
+		KeyHash: hashApiKey(rawKey),
+		Role:    role,
+	}
+	if err := a.store.ApiKey.Create(ctx, a.db, apiKey); err != nil {
+		return "", nil, err
+	}
+
+	return rawKey, apiKey, nil
+}
+
+// hashApiKey uses a plain sha256, keys are random 256 bits so a slow hash adds nothing
+func hashApiKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+type IAuth interface {
+	// Authenticate returns the active API key matching rawKey
+	Authenticate(ctx context.Context, rawKey string) (*model.ApiKey, error)
+
+	// CreateApiKey creates a new API key, the returned raw key can't be recovered later
+	CreateApiKey(ctx context.Context, name string, role model.ApiKeyRole) (string, *model.ApiKey, error)
+}
@@ -2,6 +2,7 @@ package btcrpc
 
 import (
 	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/btcaddress"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
 )
@@ -19,9 +20,17 @@ func New(appConfig *config.AppConfig, logger *logger.Logger) IBtcRpc {
 }
 
 func (b *BtcRpc) Send(receiverAddress string, amount *model.Web3BigInt) error {
+	if err := btcaddress.Validate(receiverAddress, b.appConfig.Bitcoin.Network); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (b *BtcRpc) BalanceOf(address string) (*model.Web3BigInt, error) {
 	return nil, nil
 }
+
+func (b *BtcRpc) GetLatestBlock() (*model.BtcBlock, error) {
+	return nil, nil
+}
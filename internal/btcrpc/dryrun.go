@@ -0,0 +1,56 @@
+package btcrpc
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/store"
+	"github.com/dwarvesf/icy-backend/internal/utils/btcaddress"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+var ErrMissingAmount = errors.New("btcrpc: send amount is required")
+
+type dryRunBtcRpc struct {
+	IBtcRpc
+	appConfig *config.AppConfig
+	logger    *logger.Logger
+	db        *gorm.DB
+	store     *store.Store
+}
+
+// NewDryRun wraps btcRpc so that reads pass through and sends are validated
+// and recorded in dry_run_transactions, but never broadcast
+func NewDryRun(btcRpc IBtcRpc, appConfig *config.AppConfig, logger *logger.Logger, db *gorm.DB, store *store.Store) IBtcRpc {
+	return &dryRunBtcRpc{IBtcRpc: btcRpc, appConfig: appConfig, logger: logger, db: db, store: store}
+}
+
+func (b *dryRunBtcRpc) Send(receiverAddress string, amount *model.Web3BigInt) error {
+	if amount == nil {
+		return ErrMissingAmount
+	}
+	if err := btcaddress.Validate(receiverAddress, b.appConfig.Bitcoin.Network); err != nil {
+		return err
+	}
+
+	err := b.store.DryRunTx.Create(context.Background(), b.db, &model.DryRunTransaction{
+		ReceiverAddress: receiverAddress,
+		Value:           amount.Value,
+		Decimal:         amount.Decimal,
+		Network:         string(b.appConfig.Bitcoin.Network),
+	})
+	if err != nil {
+		return err
+	}
+
+	b.logger.Info("[btcrpc] dry run, send recorded but not broadcast", map[string]string{
+		"receiver": receiverAddress,
+		"amount":   amount.Value,
+	})
+
+	return nil
+}
@@ -5,4 +5,5 @@ import "github.com/dwarvesf/icy-backend/internal/model"
 type IBtcRpc interface {
 	Send(receiverAddress string, amount *model.Web3BigInt) error
 	BalanceOf(address string) (*model.Web3BigInt, error)
+	GetLatestBlock() (*model.BtcBlock, error)
 }
@@ -0,0 +1,22 @@
+package btcrpc
+
+import (
+	"errors"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+var ErrReadOnly = errors.New("btcrpc: sending is disabled in read-only mode")
+
+type readOnlyBtcRpc struct {
+	IBtcRpc
+}
+
+// NewReadOnly wraps btcRpc so that reads pass through and every send is refused
+func NewReadOnly(btcRpc IBtcRpc) IBtcRpc {
+	return &readOnlyBtcRpc{IBtcRpc: btcRpc}
+}
+
+func (b *readOnlyBtcRpc) Send(receiverAddress string, amount *model.Web3BigInt) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,106 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+var (
+	ErrInjected        = errors.New("chaos: injected error")
+	ErrDroppedResponse = errors.New("chaos: response dropped")
+)
+
+type Chaos struct {
+	mux    *sync.RWMutex
+	faults map[string]model.ChaosFault
+
+	logger *logger.Logger
+}
+
+func New(logger *logger.Logger) IChaos {
+	return &Chaos{
+		mux:    &sync.RWMutex{},
+		faults: map[string]model.ChaosFault{},
+		logger: logger,
+	}
+}
+
+func (c *Chaos) Inject(ctx context.Context, target string) error {
+	c.mux.RLock()
+	fault, ok := c.faults[target]
+	c.mux.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if fault.LatencyMs > 0 {
+		latency := min(time.Duration(fault.LatencyMs)*time.Millisecond, MaxLatency)
+		timer := time.NewTimer(latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if fault.DropRate > 0 && rand.Float64() < fault.DropRate {
+		c.logger.Info("[chaos] dropping response", map[string]string{"target": target})
+		return ErrDroppedResponse
+	}
+
+	if fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate {
+		c.logger.Info("[chaos] injecting error", map[string]string{"target": target})
+		return ErrInjected
+	}
+
+	return nil
+}
+
+func (c *Chaos) SetFault(fault model.ChaosFault) error {
+	if !slices.Contains(Targets, fault.Target) {
+		return fmt.Errorf("unknown chaos target %q", fault.Target)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.faults[fault.Target] = fault
+
+	return nil
+}
+
+func (c *Chaos) ClearFault(target string) error {
+	if !slices.Contains(Targets, target) {
+		return fmt.Errorf("unknown chaos target %q", target)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	delete(c.faults, target)
+
+	return nil
+}
+
+func (c *Chaos) ListFaults() []model.ChaosFault {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	faults := make([]model.ChaosFault, 0, len(c.faults))
+	for _, fault := range c.faults {
+		faults = append(faults, fault)
+	}
+	sort.Slice(faults, func(i, j int) bool {
+		return faults[i].Target < faults[j].Target
+	})
+
+	return faults
+}
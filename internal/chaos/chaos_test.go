@@ -0,0 +1,57 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/oracle"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+type fakeOracle struct {
+	oracle.IOracle
+}
+
+func (fakeOracle) GetCachedCirculatedICY() (*model.Web3BigInt, error) {
+	return &model.Web3BigInt{Value: "1", Decimal: 18}, nil
+}
+
+func (fakeOracle) RefreshCache(context.Context) error {
+	return nil
+}
+
+var _ = Describe("Chaos", func() {
+	var c IChaos
+
+	BeforeEach(func() {
+		c = New(logger.New(environments.Test))
+	})
+
+	It("should stop waiting for the injected latency once the context is done", func() {
+		Expect(c.SetFault(model.ChaosFault{Target: TargetOracle, LatencyMs: time.Hour.Milliseconds()})).To(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		Expect(c.Inject(ctx, TargetOracle)).To(MatchError(context.DeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+
+	It("should inject faults into every oracle call", func() {
+		Expect(c.SetFault(model.ChaosFault{Target: TargetOracle, ErrorRate: 1})).To(Succeed())
+		o := WrapOracle(fakeOracle{}, c)
+
+		_, err := o.GetCachedCirculatedICY()
+		Expect(err).To(MatchError(ErrInjected))
+		Expect(o.RefreshCache(context.Background())).To(MatchError(ErrInjected))
+
+		Expect(c.ClearFault(TargetOracle)).To(Succeed())
+		Expect(o.GetCachedCirculatedICY()).To(HaveField("Value", "1"))
+		Expect(o.RefreshCache(context.Background())).To(Succeed())
+	})
+})
@@ -0,0 +1,28 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+const (
+	TargetBtcRpc = "btcrpc"
+	TargetOracle = "oracle"
+)
+
+var Targets = []string{TargetBtcRpc, TargetOracle}
+
+// MaxLatency caps the injected latency, so a fault can't hold calls forever
+const MaxLatency = 30 * time.Second
+
+type IChaos interface {
+	// Inject applies the fault configured for target, if any: it waits for the
+	// configured latency, or until ctx is done, and then may return an injected error
+	Inject(ctx context.Context, target string) error
+
+	SetFault(fault model.ChaosFault) error
+	ClearFault(target string) error
+	ListFaults() []model.ChaosFault
+}
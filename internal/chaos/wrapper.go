@@ -0,0 +1,115 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/btcrpc"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/oracle"
+)
+
+type chaosBtcRpc struct {
+	btcrpc.IBtcRpc
+	chaos IChaos
+}
+
+// WrapBtcRpc returns a btcrpc client that goes through the chaos injector before every call
+func WrapBtcRpc(btcRpc btcrpc.IBtcRpc, chaos IChaos) btcrpc.IBtcRpc {
+	return &chaosBtcRpc{IBtcRpc: btcRpc, chaos: chaos}
+}
+
+func (b *chaosBtcRpc) Send(receiverAddress string, amount *model.Web3BigInt) error {
+	if err := b.chaos.Inject(context.Background(), TargetBtcRpc); err != nil {
+		return err
+	}
+	return b.IBtcRpc.Send(receiverAddress, amount)
+}
+
+func (b *chaosBtcRpc) BalanceOf(address string) (*model.Web3BigInt, error) {
+	if err := b.chaos.Inject(context.Background(), TargetBtcRpc); err != nil {
+		return nil, err
+	}
+	return b.IBtcRpc.BalanceOf(address)
+}
+
+func (b *chaosBtcRpc) GetLatestBlock() (*model.BtcBlock, error) {
+	if err := b.chaos.Inject(context.Background(), TargetBtcRpc); err != nil {
+		return nil, err
+	}
+	return b.IBtcRpc.GetLatestBlock()
+}
+
+type chaosOracle struct {
+	oracle.IOracle
+	chaos IChaos
+}
+
+// WrapOracle returns an oracle whose calls go through the chaos injector,
+// except SetCacheTTL which only changes the configuration
+func WrapOracle(o oracle.IOracle, chaos IChaos) oracle.IOracle {
+	return &chaosOracle{IOracle: o, chaos: chaos}
+}
+
+func (o *chaosOracle) GetCirculatedICY() (*model.Web3BigInt, error) {
+	if err := o.chaos.Inject(context.Background(), TargetOracle); err != nil {
+		return nil, err
+	}
+	return o.IOracle.GetCirculatedICY()
+}
+
+func (o *chaosOracle) GetCachedCirculatedICY() (*model.Web3BigInt, error) {
+	if err := o.chaos.Inject(context.Background(), TargetOracle); err != nil {
+		return nil, err
+	}
+	return o.IOracle.GetCachedCirculatedICY()
+}
+
+func (o *chaosOracle) GetBTCSupply() (*model.Web3BigInt, error) {
+	if err := o.chaos.Inject(context.Background(), TargetOracle); err != nil {
+		return nil, err
+	}
+	return o.IOracle.GetBTCSupply()
+}
+
+func (o *chaosOracle) GetCachedBTCSupply() (*model.Web3BigInt, error) {
+	if err := o.chaos.Inject(context.Background(), TargetOracle); err != nil {
+		return nil, err
+	}
+	return o.IOracle.GetCachedBTCSupply()
+}
+
+func (o *chaosOracle) GetRealtimeICYBTC() (*model.Web3BigInt, error) {
+	if err := o.chaos.Inject(context.Background(), TargetOracle); err != nil {
+		return nil, err
+	}
+	return o.IOracle.GetRealtimeICYBTC()
+}
+
+func (o *chaosOracle) GetCachedRealtimeICYBTC() (*model.Web3BigInt, error) {
+	if err := o.chaos.Inject(context.Background(), TargetOracle); err != nil {
+		return nil, err
+	}
+	return o.IOracle.GetCachedRealtimeICYBTC()
+}
+
+func (o *chaosOracle) GetICYBTCHistory(ctx context.Context, from, to time.Time, interval model.RateInterval) ([]model.IcyBtcRateCandle, error) {
+	if err := o.chaos.Inject(ctx, TargetOracle); err != nil {
+		return nil, err
+	}
+	return o.IOracle.GetICYBTCHistory(ctx, from, to, interval)
+}
+
+func (o *chaosOracle) RefreshCache(ctx context.Context) error {
+	if err := o.chaos.Inject(ctx, TargetOracle); err != nil {
+		return err
+	}
+	return o.IOracle.RefreshCache(ctx)
+}
+
+func (o *chaosOracle) RecordICYBTCRate(ctx context.Context) error {
+	if err := o.chaos.Inject(ctx, TargetOracle); err != nil {
+		return err
+	}
+	return o.IOracle.RecordICYBTCRate(ctx)
+}
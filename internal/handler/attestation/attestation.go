@@ -0,0 +1,47 @@
+package attestation
+
+import (
+	"net/http"
+
+	"github.com/dwarvesf/icy-backend/internal/attestation"
+	_ "github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+	"github.com/dwarvesf/icy-backend/internal/view"
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	attestation attestation.IAttestation
+	logger      *logger.Logger
+	appConfig   *config.AppConfig
+}
+
+func New(attestation attestation.IAttestation, logger *logger.Logger, appConfig *config.AppConfig) *handler {
+	return &handler{
+		attestation: attestation,
+		logger:      logger,
+		appConfig:   appConfig,
+	}
+}
+
+// Detail godoc
+// @Summary Get Reserve Attestation
+// @Description Get a signed snapshot of the treasury BTC balance at a BTC block
+// @id getReserveAttestation
+// @Tags Attestation
+// @Accept json
+// @Produce json
+// @Success 200 {object} DataResponse{data=model.ReserveAttestation}
+// @Failure 500 {object} ErrorResponse
+// @x-versions ["v1","v2"]
+// @Router /api/v1/attestation/reserves [get]
+func (h *handler) GetReserveAttestation(c *gin.Context) {
+	reserveAttestation, err := h.attestation.AttestReserves()
+	if err != nil {
+		h.logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeAttestationUnavailable, c.GetHeader("Accept-Language")))
+		return
+	}
+	c.JSON(http.StatusOK, view.CreateResponse[any](reserveAttestation, nil, "", ""))
+}
@@ -0,0 +1,7 @@
+package attestation
+
+import "github.com/gin-gonic/gin"
+
+type IHandler interface {
+	GetReserveAttestation(c *gin.Context)
+}
@@ -0,0 +1,99 @@
+package chaos
+
+import (
+	"net/http"
+
+	"github.com/dwarvesf/icy-backend/internal/chaos"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+	"github.com/dwarvesf/icy-backend/internal/view"
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	chaos     chaos.IChaos
+	logger    *logger.Logger
+	appConfig *config.AppConfig
+}
+
+func New(chaos chaos.IChaos, logger *logger.Logger, appConfig *config.AppConfig) *handler {
+	return &handler{
+		chaos:     chaos,
+		logger:    logger,
+		appConfig: appConfig,
+	}
+}
+
+// Detail godoc
+// @Summary List Chaos Faults
+// @Description List the faults currently injected, only available outside production
+// @id listChaosFaults
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} DataResponse{data=[]model.ChaosFault}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/chaos [get]
+func (h *handler) ListFaults(c *gin.Context) {
+	c.JSON(http.StatusOK, view.CreateResponse[any](h.chaos.ListFaults(), nil, "", ""))
+}
+
+// Detail godoc
+// @Summary Set Chaos Fault
+// @Description Inject latency, errors or dropped responses into a target (btcrpc, oracle)
+// @id setChaosFault
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param target path string true "chaos target"
+// @Param fault body model.ChaosFault true "fault to inject"
+// @Success 200 {object} DataResponse{data=model.ChaosFault}
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/chaos/{target} [put]
+func (h *handler) SetFault(c *gin.Context) {
+	var req model.ChaosFault
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, view.CreateErrorResponse(err, req, view.ErrCodeInvalidChaosFault, c.GetHeader("Accept-Language")))
+		return
+	}
+	req.Target = c.Param("target")
+
+	if err := h.chaos.SetFault(req); err != nil {
+		c.JSON(http.StatusBadRequest, view.CreateErrorResponse(err, nil, view.ErrCodeChaosTargetUnknown, c.GetHeader("Accept-Language")))
+		return
+	}
+
+	h.logger.Info("[chaos] fault set", map[string]string{"target": req.Target})
+	c.JSON(http.StatusOK, view.CreateResponse[any](req, nil, "", ""))
+}
+
+// Detail godoc
+// @Summary Clear Chaos Fault
+// @Description Stop injecting faults into a target
+// @id clearChaosFault
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param target path string true "chaos target"
+// @Success 200 {object} DataResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/chaos/{target} [delete]
+func (h *handler) ClearFault(c *gin.Context) {
+	target := c.Param("target")
+	if err := h.chaos.ClearFault(target); err != nil {
+		c.JSON(http.StatusBadRequest, view.CreateErrorResponse(err, nil, view.ErrCodeChaosTargetUnknown, c.GetHeader("Accept-Language")))
+		return
+	}
+
+	h.logger.Info("[chaos] fault cleared", map[string]string{"target": target})
+	c.JSON(http.StatusOK, view.CreateResponse[any](nil, nil, "", "fault cleared"))
+}
@@ -0,0 +1,9 @@
+package chaos
+
+import "github.com/gin-gonic/gin"
+
+type IHandler interface {
+	ListFaults(c *gin.Context)
+	SetFault(c *gin.Context)
+	ClearFault(c *gin.Context)
+}
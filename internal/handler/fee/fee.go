@@ -0,0 +1,78 @@
+package fee
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+	"github.com/dwarvesf/icy-backend/internal/view"
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	mux *sync.Mutex
+	// fee is reloadable, it is not read from appConfig
+	fee config.FeeConfig
+
+	logger    *logger.Logger
+	appConfig *config.AppConfig
+}
+
+func New(logger *logger.Logger, appConfig *config.AppConfig) *handler {
+	return &handler{
+		mux:       &sync.Mutex{},
+		fee:       appConfig.Fee,
+		logger:    logger,
+		appConfig: appConfig,
+	}
+}
+
+func (h *handler) SetFeeConfig(fee config.FeeConfig) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.fee = fee
+}
+
+// Detail godoc
+// @Summary Get Fee Schedule
+// @Description Get the current fee schedule, including service fee tiers, network fee policy and sponsorship caps
+// @id getFeeSchedule
+// @Tags Fee
+// @Accept json
+// @Produce json
+// @Success 200 {object} DataResponse{data=model.FeeSchedule}
+// @x-versions ["v1","v2"]
+// @Router /api/v1/fees [get]
+func (h *handler) GetFeeSchedule(c *gin.Context) {
+	h.mux.Lock()
+	feeCfg := h.fee
+	h.mux.Unlock()
+
+	tiers := make([]model.ServiceFeeTier, 0, len(feeCfg.ServiceFeeTiers))
+	for _, tier := range feeCfg.ServiceFeeTiers {
+		tiers = append(tiers, model.ServiceFeeTier{
+			MinAmount: &model.Web3BigInt{
+				Value:   tier.MinAmount,
+				Decimal: 18,
+			},
+			FeeBps: tier.FeeBps,
+		})
+	}
+
+	feeSchedule := model.FeeSchedule{
+		Version:          feeCfg.Version,
+		EffectiveFrom:    feeCfg.EffectiveFrom,
+		ServiceFeeTiers:  tiers,
+		MinSatoshiFee:    feeCfg.MinSatoshiFee,
+		DustThreshold:    feeCfg.DustThreshold,
+		NetworkFeePolicy: feeCfg.NetworkFeePolicy,
+		SponsorshipCaps: model.SponsorshipCaps{
+			PerSwapSatoshi: feeCfg.SponsorshipCapPerSwap,
+			DailySatoshi:   feeCfg.SponsorshipCapDaily,
+		},
+	}
+
+	c.JSON(http.StatusOK, view.CreateResponse[any](feeSchedule, nil, "", ""))
+}
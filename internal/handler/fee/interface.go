@@ -0,0 +1,14 @@
+package fee
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+)
+
+type IHandler interface {
+	GetFeeSchedule(c *gin.Context)
+
+	// SetFeeConfig replaces the served fee schedule, e.g on config reload
+	SetFeeConfig(fee config.FeeConfig)
+}
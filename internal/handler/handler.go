@@ -1,18 +1,51 @@
 package handler
 
 import (
+	attestationService "github.com/dwarvesf/icy-backend/internal/attestation"
+	chaosService "github.com/dwarvesf/icy-backend/internal/chaos"
+	"github.com/dwarvesf/icy-backend/internal/handler/attestation"
+	"github.com/dwarvesf/icy-backend/internal/handler/chaos"
+	"github.com/dwarvesf/icy-backend/internal/handler/fee"
+	"github.com/dwarvesf/icy-backend/internal/handler/health"
+	"github.com/dwarvesf/icy-backend/internal/handler/job"
 	"github.com/dwarvesf/icy-backend/internal/handler/oracle"
+	"github.com/dwarvesf/icy-backend/internal/handler/stream"
+	healthService "github.com/dwarvesf/icy-backend/internal/health"
 	oracleService "github.com/dwarvesf/icy-backend/internal/oracle"
+	schedulerService "github.com/dwarvesf/icy-backend/internal/scheduler"
+	streamService "github.com/dwarvesf/icy-backend/internal/stream"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
 )
 
 type Handler struct {
-	OracleHandler oracle.IHandler
+	OracleHandler      oracle.IHandler
+	FeeHandler         fee.IHandler
+	StreamHandler      stream.IHandler
+	HealthHandler      health.IHandler
+	AttestationHandler attestation.IHandler
+	ChaosHandler       chaos.IHandler
+	JobHandler         job.IHandler
 }
 
-func New(appConfig *config.AppConfig, logger *logger.Logger, oracleSvc oracleService.IOracle) *Handler {
-	return &Handler{
+func New(appConfig *config.AppConfig, logger *logger.Logger, oracleSvc oracleService.IOracle, streamHub streamService.IHub, healthSvc healthService.IHealth, attestationSvc attestationService.IAttestation, chaosSvc chaosService.IChaos, schedulerSvc schedulerService.IScheduler) *Handler {
+	h := &Handler{
 		OracleHandler: oracle.New(oracleSvc, logger, appConfig),
+		FeeHandler:    fee.New(logger, appConfig),
+		StreamHandler: stream.New(streamHub, logger, appConfig),
+		HealthHandler: health.New(healthSvc, logger, appConfig),
 	}
+
+	// optional subsystems, their routes are only registered when they are enabled
+	if attestationSvc != nil {
+		h.AttestationHandler = attestation.New(attestationSvc, logger, appConfig)
+	}
+	if chaosSvc != nil {
+		h.ChaosHandler = chaos.New(chaosSvc, logger, appConfig)
+	}
+	if schedulerSvc != nil {
+		h.JobHandler = job.New(schedulerSvc, logger, appConfig)
+	}
+
+	return h
 }
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/dwarvesf/icy-backend/internal/health"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+	"github.com/dwarvesf/icy-backend/internal/view"
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	health    health.IHealth
+	logger    *logger.Logger
+	appConfig *config.AppConfig
+}
+
+func New(health health.IHealth, logger *logger.Logger, appConfig *config.AppConfig) *handler {
+	return &handler{
+		health:    health,
+		logger:    logger,
+		appConfig: appConfig,
+	}
+}
+
+// Detail godoc
+// @Summary Liveness
+// @Description Liveness probe, reports whether the process is up without probing dependencies
+// @id liveness
+// @Tags Health
+// @Produce json
+// @Success 200 {object} MessageResponse
+// @Router /healthz [get]
+func (h *handler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, view.MessageResponse{Message: "ok"})
+}
+
+// Detail godoc
+// @Summary Readiness
+// @Description Readiness probe, reports the status of every dependency
+// @id readiness
+// @Tags Health
+// @Produce json
+// @Success 200 {object} model.HealthStatus
+// @Failure 503 {object} model.HealthStatus
+// @Router /readyz [get]
+func (h *handler) Readiness(c *gin.Context) {
+	status := h.health.Readiness(c.Request.Context())
+	if status.Status != model.HealthStatusOk {
+		c.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,8 @@
+package health
+
+import "github.com/gin-gonic/gin"
+
+type IHandler interface {
+	Liveness(c *gin.Context)
+	Readiness(c *gin.Context)
+}
@@ -0,0 +1,9 @@
+package job
+
+import "github.com/gin-gonic/gin"
+
+type IHandler interface {
+	ListJobs(c *gin.Context)
+	GetJob(c *gin.Context)
+	TriggerJob(c *gin.Context)
+}
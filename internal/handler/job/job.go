@@ -0,0 +1,97 @@
+package job
+
+import (
+	"errors"
+	"net/http"
+
+	_ "github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/scheduler"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+	"github.com/dwarvesf/icy-backend/internal/view"
+	"github.com/gin-gonic/gin"
+)
+
+type handler struct {
+	scheduler scheduler.IScheduler
+	logger    *logger.Logger
+	appConfig *config.AppConfig
+}
+
+func New(scheduler scheduler.IScheduler, logger *logger.Logger, appConfig *config.AppConfig) *handler {
+	return &handler{
+		scheduler: scheduler,
+		logger:    logger,
+		appConfig: appConfig,
+	}
+}
+
+// Detail godoc
+// @Summary List Jobs
+// @Description List the background jobs with their last run, duration, failures and stalled state
+// @id listJobs
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} DataResponse{data=[]model.JobStatus}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/jobs [get]
+func (h *handler) ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, view.CreateResponse[any](h.scheduler.ListJobs(), nil, "", ""))
+}
+
+// Detail godoc
+// @Summary Get Job
+// @Description Get the status of a background job
+// @id getJob
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param name path string true "job name, e.g oracle-refresh"
+// @Success 200 {object} DataResponse{data=model.JobStatus}
+// @Failure 404 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/jobs/{name} [get]
+func (h *handler) GetJob(c *gin.Context) {
+	status, err := h.scheduler.GetJob(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, view.CreateErrorResponse(err, nil, view.ErrCodeJobNotFound, c.GetHeader("Accept-Language")))
+		return
+	}
+	c.JSON(http.StatusOK, view.CreateResponse[any](status, nil, "", ""))
+}
+
+// Detail godoc
+// @Summary Trigger Job
+// @Description Run a background job now, outside of its schedule
+// @id triggerJob
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param name path string true "job name, e.g oracle-refresh"
+// @Success 202 {object} DataResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/v1/admin/jobs/{name}/run [post]
+func (h *handler) TriggerJob(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.scheduler.Trigger(name); err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, view.CreateErrorResponse(err, nil, view.ErrCodeJobNotFound, c.GetHeader("Accept-Language")))
+		default:
+			c.JSON(http.StatusConflict, view.CreateErrorResponse(err, nil, view.ErrCodeJobRunning, c.GetHeader("Accept-Language")))
+		}
+		return
+	}
+
+	h.logger.Info("[job] job triggered", map[string]string{"job": name})
+	c.JSON(http.StatusAccepted, view.CreateResponse[any](nil, nil, "", "job triggered"))
+}
@@ -4,7 +4,10 @@ import "github.com/gin-gonic/gin"
 
 type IHandler interface {
 	GetCirculatedICY(c *gin.Context)
+	GetCirculatedICYCached(c *gin.Context)
 	GetTreasusyBTC(c *gin.Context)
+	GetTreasuryBTCCached(c *gin.Context)
 	GetICYBTCRatio(c *gin.Context)
 	GetICYBTCRatioCached(c *gin.Context)
+	GetICYBTCRateHistory(c *gin.Context)
 }
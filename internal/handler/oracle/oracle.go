@@ -2,8 +2,9 @@ package oracle
 
 import (
 	"net/http"
+	"time"
 
-	_ "github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/model"
 	"github.com/dwarvesf/icy-backend/internal/oracle"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
@@ -32,14 +33,37 @@ func New(oracle oracle.IOracle, logger *logger.Logger, appConfig *config.AppConf
 // @Tags Oracle
 // @Accept json
 // @Produce json
-// @Success 200 {object} model.Web3BigInt
+// @Success 200 {object} DataResponse{data=model.Web3BigInt}
 // @Failure 500 {object} ErrorResponse
-// @Router /oracle/circulated-icy [get]
+// @x-versions ["v1","v2"]
+// @Router /api/v1/oracle/circulated-icy [get]
 func (h *handler) GetCirculatedICY(c *gin.Context) {
 	circulatedICY, err := h.oracle.GetCirculatedICY()
 	if err != nil {
 		h.logger.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, view.CreateResponse[any](nil, err, "", "can't get circulated ICY"))
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeCirculatedICYUnavailable, c.GetHeader("Accept-Language")))
+		return
+	}
+	c.JSON(http.StatusOK, view.CreateResponse[any](circulatedICY, nil, "", ""))
+	return
+}
+
+// Detail godoc
+// @Summary Get cached Circulated ICY
+// @Description Get cached Circulated ICY
+// @id getCirculatedICYCached
+// @Tags Oracle
+// @Accept json
+// @Produce json
+// @Success 200 {object} DataResponse{data=model.Web3BigInt}
+// @Failure 500 {object} ErrorResponse
+// @x-versions ["v1","v2"]
+// @Router /api/v1/oracle/circulated-icy-cached [get]
+func (h *handler) GetCirculatedICYCached(c *gin.Context) {
+	circulatedICY, err := h.oracle.GetCachedCirculatedICY()
+	if err != nil {
+		h.logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeCachedCirculatedICYUnavailable, c.GetHeader("Accept-Language")))
 		return
 	}
 	c.JSON(http.StatusOK, view.CreateResponse[any](circulatedICY, nil, "", ""))
@@ -53,14 +77,37 @@ func (h *handler) GetCirculatedICY(c *gin.Context) {
 // @Tags Oracle
 // @Accept json
 // @Produce json
-// @Success 200 {object} model.Web3BigInt
+// @Success 200 {object} DataResponse{data=model.Web3BigInt}
 // @Failure 500 {object} ErrorResponse
-// @Router /oracle/treasury-btc [get]
+// @x-versions ["v1","v2"]
+// @Router /api/v1/oracle/treasury-btc [get]
 func (h *handler) GetTreasusyBTC(c *gin.Context) {
 	treasuryBTC, err := h.oracle.GetBTCSupply()
 	if err != nil {
 		h.logger.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, view.CreateResponse[any](nil, err, "", "can't get treasury BTC"))
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeTreasuryBTCUnavailable, c.GetHeader("Accept-Language")))
+		return
+	}
+	c.JSON(http.StatusOK, view.CreateResponse[any](treasuryBTC, nil, "", ""))
+	return
+}
+
+// Detail godoc
+// @Summary Get cached Treasury BTC
+// @Description Get cached Treasury BTC
+// @id getTreasuryBTCCached
+// @Tags Oracle
+// @Accept json
+// @Produce json
+// @Success 200 {object} DataResponse{data=model.Web3BigInt}
+// @Failure 500 {object} ErrorResponse
+// @x-versions ["v1","v2"]
+// @Router /api/v1/oracle/treasury-btc-cached [get]
+func (h *handler) GetTreasuryBTCCached(c *gin.Context) {
+	treasuryBTC, err := h.oracle.GetCachedBTCSupply()
+	if err != nil {
+		h.logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeCachedTreasuryBTCUnavailable, c.GetHeader("Accept-Language")))
 		return
 	}
 	c.JSON(http.StatusOK, view.CreateResponse[any](treasuryBTC, nil, "", ""))
@@ -74,14 +121,15 @@ func (h *handler) GetTreasusyBTC(c *gin.Context) {
 // @Tags Oracle
 // @Accept json
 // @Produce json
-// @Success 200 {object} model.Web3BigInt
+// @Success 200 {object} DataResponse{data=model.Web3BigInt}
 // @Failure 500 {object} ErrorResponse
-// @Router /oracle/icy-btc-ratio [get]
+// @x-versions ["v1","v2"]
+// @Router /api/v1/oracle/icy-btc-ratio [get]
 func (h *handler) GetICYBTCRatio(c *gin.Context) {
 	realtimeICYBTC, err := h.oracle.GetRealtimeICYBTC()
 	if err != nil {
 		h.logger.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, view.CreateResponse[any](nil, err, "", "can't get realtime ICY/BTC price"))
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeRealtimePriceUnavailable, c.GetHeader("Accept-Language")))
 		return
 	}
 	c.JSON(http.StatusOK, view.CreateResponse[any](realtimeICYBTC, nil, "", ""))
@@ -95,16 +143,68 @@ func (h *handler) GetICYBTCRatio(c *gin.Context) {
 // @Tags Oracle
 // @Accept json
 // @Produce json
-// @Success 200 {object} model.Web3BigInt
+// @Success 200 {object} DataResponse{data=model.Web3BigInt}
 // @Failure 500 {object} ErrorResponse
-// @Router /oracle/icy-btc-ratio-cached [get]
+// @x-versions ["v1","v2"]
+// @Router /api/v1/oracle/icy-btc-ratio-cached [get]
 func (h *handler) GetICYBTCRatioCached(c *gin.Context) {
 	cachedRealtimeICYBTC, err := h.oracle.GetCachedRealtimeICYBTC()
 	if err != nil {
 		h.logger.Error(err.Error())
-		c.JSON(http.StatusInternalServerError, view.CreateResponse[any](nil, err, "", "can't get cached ICY/BTC price"))
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeCachedPriceUnavailable, c.GetHeader("Accept-Language")))
 		return
 	}
 	c.JSON(http.StatusOK, view.CreateResponse[any](cachedRealtimeICYBTC, nil, "", ""))
 	return
 }
+
+// maxRateHistoryCandles bounds the range of a single history request
+const maxRateHistoryCandles = 1000
+
+// Detail godoc
+// @Summary Get ICY/BTC price history
+// @Description Get the OHLC of the recorded ICY/BTC prices per interval, from and to default to the last 7 days
+// @id getICYBTCRateHistory
+// @Tags Oracle
+// @Accept json
+// @Produce json
+// @Param from query string false "start time (RFC3339), inclusive"
+// @Param to query string false "end time (RFC3339), exclusive"
+// @Param interval query string false "candle interval" Enums(hour, day) default(hour)
+// @Success 200 {object} DataResponse{data=[]model.IcyBtcRateCandle}
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @x-versions ["v1","v2"]
+// @Router /api/v1/rates/history [get]
+func (h *handler) GetICYBTCRateHistory(c *gin.Context) {
+	lang := c.GetHeader("Accept-Language")
+
+	interval := model.RateInterval(c.DefaultQuery("interval", string(model.RateIntervalHour)))
+	to, err := parseTimeQuery(c, "to", time.Now())
+	if err != nil || !interval.IsValid() {
+		c.JSON(http.StatusBadRequest, view.CreateErrorResponse(err, nil, view.ErrCodeInvalidRateHistoryQuery, lang))
+		return
+	}
+	from, err := parseTimeQuery(c, "from", to.Add(-7*24*time.Hour))
+	if err != nil || !from.Before(to) || to.Sub(from) > maxRateHistoryCandles*interval.Duration() {
+		c.JSON(http.StatusBadRequest, view.CreateErrorResponse(err, nil, view.ErrCodeInvalidRateHistoryQuery, lang))
+		return
+	}
+
+	candles, err := h.oracle.GetICYBTCHistory(c.Request.Context(), from, to, interval)
+	if err != nil {
+		h.logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, view.CreateErrorResponse(err, nil, view.ErrCodeRateHistoryUnavailable, lang))
+		return
+	}
+	c.JSON(http.StatusOK, view.CreateResponse[any](candles, nil, "", ""))
+}
+
+func parseTimeQuery(c *gin.Context, key string, defaultValue time.Time) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
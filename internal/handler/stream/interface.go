@@ -0,0 +1,7 @@
+package stream
+
+import "github.com/gin-gonic/gin"
+
+type IHandler interface {
+	StreamUpdates(c *gin.Context)
+}
@@ -0,0 +1,68 @@
+package stream
+
+import (
+	"io"
+	"time"
+
+	_ "github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/stream"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type handler struct {
+	hub       stream.IHub
+	logger    *logger.Logger
+	appConfig *config.AppConfig
+}
+
+func New(hub stream.IHub, logger *logger.Logger, appConfig *config.AppConfig) *handler {
+	return &handler{
+		hub:       hub,
+		logger:    logger,
+		appConfig: appConfig,
+	}
+}
+
+// Detail godoc
+// @Summary Stream Updates
+// @Description Server-sent events stream of realtime updates. Event `icy_btc_ratio` carries the ICY/BTC price, `ping` is sent as a heartbeat
+// @id streamUpdates
+// @Tags Stream
+// @Produce text/event-stream
+// @Success 200 {string} string "event stream, the data of `icy_btc_ratio` events is a Web3BigInt"
+// @x-versions ["v1","v2"]
+// @Router /api/v1/stream [get]
+func (h *handler) StreamUpdates(c *gin.Context) {
+	events, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	// set before the first event, so clients see an event stream right away
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case event, ok := <-events:
+			if !ok {
+				// hub stopped, the server is shutting down
+				return false
+			}
+			c.SSEvent(event.Type, event.Data)
+			return true
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+type Health struct {
+	mux             *sync.Mutex
+	cachedReadiness *model.HealthStatus
+
+	probes    []Probe
+	appConfig *config.AppConfig
+	logger    *logger.Logger
+}
+
+func New(appConfig *config.AppConfig, logger *logger.Logger, probes ...Probe) IHealth {
+	return &Health{
+		mux:       &sync.Mutex{},
+		probes:    probes,
+		appConfig: appConfig,
+		logger:    logger,
+	}
+}
+
+func (h *Health) Readiness(ctx context.Context) *model.HealthStatus {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.cachedReadiness != nil && time.Since(h.cachedReadiness.CheckedAt) < h.appConfig.Health.CacheTTL {
+		return h.cachedReadiness
+	}
+
+	checks := make([]model.HealthCheck, len(h.probes))
+	wg := &sync.WaitGroup{}
+	for i, probe := range h.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the result is cached, so a client hanging up must not fail the probes
+			checks[i] = h.runProbe(context.WithoutCancel(ctx), probe)
+		}()
+	}
+	wg.Wait()
+
+	status := &model.HealthStatus{
+		Status:    model.HealthStatusOk,
+		Checks:    checks,
+		CheckedAt: time.Now().UTC(),
+	}
+	for _, check := range checks {
+		if check.Status != model.HealthStatusOk {
+			status.Status = model.HealthStatusUnavailable
+			h.logger.Error("[health] dependency is unavailable", map[string]string{
+				"dependency": check.Name,
+				"error":      check.Error,
+			})
+		}
+	}
+
+	h.cachedReadiness = status
+	return status
+}
+
+func (h *Health) runProbe(ctx context.Context, probe Probe) model.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, h.appConfig.Health.ProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := probe.Check(ctx)
+
+	check := model.HealthCheck{
+		Name:      probe.Name,
+		Status:    model.HealthStatusOk,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		check.Status = model.HealthStatusUnavailable
+		check.Error = err.Error()
+	}
+
+	return check
+}
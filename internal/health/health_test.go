@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+var _ = Describe("Health", func() {
+	var (
+		appConfig *config.AppConfig
+		log       *logger.Logger
+	)
+
+	BeforeEach(func() {
+		appConfig = &config.AppConfig{
+			Health: config.HealthConfig{
+				ProbeTimeout: 50 * time.Millisecond,
+				CacheTTL:     time.Minute,
+			},
+		}
+		log = logger.New(environments.Test)
+	})
+
+	Describe("#Readiness", func() {
+		It("should be ok when every probe succeeds", func() {
+			h := New(appConfig, log, Probe{Name: "postgres", Check: func(ctx context.Context) error { return nil }})
+
+			status := h.Readiness(context.Background())
+			Expect(status.Status).To(Equal(model.HealthStatusOk))
+			Expect(status.Checks).To(HaveLen(1))
+			Expect(status.Checks[0].Name).To(Equal("postgres"))
+		})
+
+		It("should be unavailable when a probe fails", func() {
+			h := New(appConfig, log,
+				Probe{Name: "postgres", Check: func(ctx context.Context) error { return nil }},
+				Probe{Name: "btc", Check: func(ctx context.Context) error { return errors.New("down") }},
+			)
+
+			status := h.Readiness(context.Background())
+			Expect(status.Status).To(Equal(model.HealthStatusUnavailable))
+			Expect(status.Checks[1].Error).To(Equal("down"))
+		})
+
+		It("should time out slow probes", func() {
+			h := New(appConfig, log, Probe{Name: "slow", Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}})
+
+			status := h.Readiness(context.Background())
+			Expect(status.Status).To(Equal(model.HealthStatusUnavailable))
+			Expect(status.Checks[0].Error).To(Equal(context.DeadlineExceeded.Error()))
+		})
+
+		It("should not fail the probes when the client hangs up", func() {
+			h := New(appConfig, log, Probe{Name: "postgres", Check: func(ctx context.Context) error {
+				return ctx.Err()
+			}})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			status := h.Readiness(ctx)
+			Expect(status.Status).To(Equal(model.HealthStatusOk))
+		})
+
+		It("should cache the result within the TTL", func() {
+			calls := 0
+			h := New(appConfig, log, Probe{Name: "postgres", Check: func(ctx context.Context) error {
+				calls++
+				return nil
+			}})
+
+			h.Readiness(context.Background())
+			h.Readiness(context.Background())
+			Expect(calls).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,19 @@
+package health
+
+import (
+	"context"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+// Probe checks a single dependency, Check must return once ctx is done
+type Probe struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type IHealth interface {
+	// Readiness probes every dependency, results are cached for a short time so
+	// frequent probes don't hammer the dependencies
+	Readiness(ctx context.Context) *model.HealthStatus
+}
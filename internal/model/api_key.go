@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+type ApiKeyRole string
+
+const (
+	ApiKeyRoleReadOnly ApiKeyRole = "read-only"
+	ApiKeyRoleOperator ApiKeyRole = "operator"
+	ApiKeyRoleAdmin    ApiKeyRole = "admin"
+)
+
+var apiKeyRoleRanks = map[ApiKeyRole]int{
+	ApiKeyRoleReadOnly: 1,
+	ApiKeyRoleOperator: 2,
+	ApiKeyRoleAdmin:    3,
+}
+
+// Includes reports whether role r grants every permission of role other,
+// e.g admin includes operator and read-only
+func (r ApiKeyRole) Includes(other ApiKeyRole) bool {
+	rank, ok := apiKeyRoleRanks[r]
+	return ok && rank >= apiKeyRoleRanks[other]
+}
+
+func (r ApiKeyRole) IsValid() bool {
+	_, ok := apiKeyRoleRanks[r]
+	return ok
+}
+
+// ApiKey only stores the sha256 hash of the key, the raw key is shown once at creation
+type ApiKey struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Name      string     `json:"name" gorm:"not null"`
+	KeyHash   string     `json:"-" gorm:"uniqueIndex;not null"`
+	Role      ApiKeyRole `json:"role" gorm:"not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
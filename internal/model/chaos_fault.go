@@ -0,0 +1,8 @@
+package model
+
+type ChaosFault struct {
+	Target    string  `json:"target"`
+	LatencyMs int64   `json:"latency_ms" binding:"gte=0,lte=30000"`
+	ErrorRate float64 `json:"error_rate" binding:"gte=0,lte=1"`
+	DropRate  float64 `json:"drop_rate" binding:"gte=0,lte=1"`
+}
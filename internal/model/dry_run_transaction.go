@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// DryRunTransaction is a BTC send simulated in dry-run mode, the amount is
+// stored in the same scale as Web3BigInt
+type DryRunTransaction struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	ReceiverAddress string    `json:"receiver_address" gorm:"not null"`
+	Value           string    `json:"value" gorm:"type:numeric;not null"`
+	Decimal         int       `json:"decimal" gorm:"not null"`
+	Network         string    `json:"network" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at" gorm:"index"`
+}
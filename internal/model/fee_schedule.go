@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type FeeSchedule struct {
+	Version          string           `json:"version"`
+	EffectiveFrom    time.Time        `json:"effective_from"`
+	ServiceFeeTiers  []ServiceFeeTier `json:"service_fee_tiers"`
+	MinSatoshiFee    int64            `json:"min_satoshi_fee"`
+	DustThreshold    int64            `json:"dust_threshold"`
+	NetworkFeePolicy string           `json:"network_fee_policy"`
+	SponsorshipCaps  SponsorshipCaps  `json:"sponsorship_caps"`
+}
+
+type ServiceFeeTier struct {
+	MinAmount *Web3BigInt `json:"min_amount"`
+	FeeBps    int         `json:"fee_bps"`
+}
+
+type SponsorshipCaps struct {
+	PerSwapSatoshi int64 `json:"per_swap_satoshi"`
+	DailySatoshi   int64 `json:"daily_satoshi"`
+}
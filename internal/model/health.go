@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	HealthStatusOk          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+type HealthStatus struct {
+	Status    string        `json:"status"`
+	Checks    []HealthCheck `json:"checks,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+type HealthCheck struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// IcyBtcRate is a recorded ICY/BTC rate, stored in the same scale as Web3BigInt
+type IcyBtcRate struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	Value     string    `json:"value" gorm:"type:numeric;not null"`
+	Decimal   int       `json:"decimal" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+type RateInterval string
+
+const (
+	RateIntervalHour RateInterval = "hour"
+	RateIntervalDay  RateInterval = "day"
+)
+
+func (i RateInterval) Duration() time.Duration {
+	switch i {
+	case RateIntervalHour:
+		return time.Hour
+	case RateIntervalDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+func (i RateInterval) IsValid() bool {
+	return i.Duration() > 0
+}
+
+// IcyBtcRateCandle is the OHLC of the ICY/BTC rate over one interval starting at Time
+type IcyBtcRateCandle struct {
+	Time    time.Time `json:"time"`
+	Open    string    `json:"open"`
+	High    string    `json:"high"`
+	Low     string    `json:"low"`
+	Close   string    `json:"close"`
+	Decimal int       `json:"decimal"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// JobLease makes a scheduled job single-flight across replicas. LastTick is the
+// latest scheduled run claimed by a replica, which holds the lease until
+// LockedUntil unless it releases it earlier
+type JobLease struct {
+	Name        string    `gorm:"primaryKey"`
+	LastTick    time.Time `gorm:"not null"`
+	LockedUntil time.Time `gorm:"not null"`
+}
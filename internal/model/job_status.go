@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type JobStatus struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Running  bool   `json:"running"`
+	// Stalled is set when the current run takes longer than the stall timeout of the job
+	Stalled             bool       `json:"stalled"`
+	LastStartedAt       *time.Time `json:"last_started_at"`
+	LastFinishedAt      *time.Time `json:"last_finished_at"`
+	LastSucceededAt     *time.Time `json:"last_succeeded_at"`
+	LastDurationMs      int64      `json:"last_duration_ms"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	// LockSkips counts the runs skipped because another replica held the job
+	// lease or already ran the scheduled tick
+	LockSkips int        `json:"lock_skips"`
+	NextRunAt *time.Time `json:"next_run_at"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+type BtcBlock struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+type ReserveSnapshot struct {
+	BtcTreasuryAddress string      `json:"btc_treasury_address"`
+	BtcBalance         *Web3BigInt `json:"btc_balance"`
+	BtcBlock           *BtcBlock   `json:"btc_block"`
+	Timestamp          time.Time   `json:"timestamp"`
+}
+
+type ReserveAttestation struct {
+	Snapshot     ReserveSnapshot `json:"snapshot"`
+	Payload      string          `json:"payload"`
+	Signature    string          `json:"signature"`
+	PublicKey    string          `json:"public_key"`
+	Algorithm    string          `json:"algorithm"`
+	Verification string          `json:"verification"`
+}
@@ -0,0 +1,75 @@
+package openapi
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// optionalFields returns the omitempty JSON fields of the structs under
+// root/internal, keyed by their swag definition name: package.Type, or the
+// name given by a trailing `// @name` comment
+func optionalFields(root string) (map[string]map[string]bool, error) {
+	optional := map[string]map[string]bool{}
+	fset := token.NewFileSet()
+
+	err := filepath.WalkDir(filepath.Join(root, "internal"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			typeSpec, ok := node.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+
+			fields := map[string]bool{}
+			for _, field := range structType.Fields.List {
+				if field.Tag == nil {
+					continue
+				}
+				tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json")
+				if name, options, _ := strings.Cut(tag, ","); name != "" && strings.Contains(options, "omitempty") {
+					fields[name] = true
+				}
+			}
+
+			optional[file.Name.Name+"."+typeSpec.Name.Name] = fields
+			if alias := definitionAlias(fset, file, structType); alias != "" {
+				optional[alias] = fields
+			}
+			return true
+		})
+		return nil
+	})
+
+	return optional, err
+}
+
+// definitionAlias returns the name set by a `// @name` comment right after the struct
+func definitionAlias(fset *token.FileSet, file *ast.File, structType *ast.StructType) string {
+	line := fset.Position(structType.End()).Line
+	for _, group := range file.Comments {
+		if fset.Position(group.Pos()).Line != line {
+			continue
+		}
+		if name, ok := strings.CutPrefix(strings.TrimSpace(group.Text()), "@name "); ok {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
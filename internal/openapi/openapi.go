@@ -0,0 +1,434 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/swaggo/swag"
+)
+
+const (
+	// MainAPIFile holds the general API annotations, relative to the repo root
+	MainAPIFile = "cmd/server/main.go"
+
+	// SpecFile and ClientFile are the generated artifacts, relative to the repo root
+	SpecFile   = "docs/openapi.json"
+	ClientFile = "clients/typescript/icy-backend.ts"
+
+	// versionsExtension lists the API versions serving a /api/v1 operation, set
+	// with e.g `// @x-versions ["v1","v2"]`. swag can't give one handler several
+	// routes with distinct ids
+	versionsExtension = "x-versions"
+)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps a lower case HTTP method to its operation
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// RefName returns the component name a $ref points to
+func (s *Schema) RefName() string {
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+// Generate parses the handler annotations of the repo at root and returns the
+// OpenAPI 3 document. Schemas no operation uses are left out
+func Generate(root string) (*Document, error) {
+	parser := swag.New(swag.SetDebugger(log.New(io.Discard, "", 0)))
+	parser.RequiredByDefault = true
+	if err := parser.ParseAPI(root, MainAPIFile, 100); err != nil {
+		return nil, err
+	}
+
+	optional, err := optionalFields(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(parser.GetSwagger(), optional)
+}
+
+// Marshal returns the document as written to SpecFile
+func (d *Document) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+func convert(swagger *spec.Swagger, optional map[string]map[string]bool) (*Document, error) {
+	names, err := componentNames(swagger.Definitions)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       swagger.Info.Title,
+			Description: swagger.Info.Description,
+			Version:     swagger.Info.Version,
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{},
+		},
+	}
+
+	for path, item := range swagger.Paths.Paths {
+		operations := map[string]*spec.Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPut:    item.Put,
+			http.MethodPost:   item.Post,
+			http.MethodDelete: item.Delete,
+			http.MethodPatch:  item.Patch,
+			http.MethodHead:   item.Head,
+		}
+		for method, op := range operations {
+			if op == nil {
+				continue
+			}
+			if op.ID == "" {
+				return nil, fmt.Errorf("%s %s: missing @id annotation", method, path)
+			}
+
+			versioned, err := versionedPaths(path, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			for versionedPath, id := range versioned {
+				converted := convertOperation(op, names)
+				converted.OperationID = id
+				if doc.Paths[versionedPath] == nil {
+					doc.Paths[versionedPath] = PathItem{}
+				}
+				doc.Paths[versionedPath][strings.ToLower(method)] = converted
+			}
+		}
+	}
+
+	operationIDs := map[string]string{}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			if previous, ok := operationIDs[op.OperationID]; ok {
+				return nil, fmt.Errorf("%s %s: operation id %s is already used by %s", method, path, op.OperationID, previous)
+			}
+			operationIDs[op.OperationID] = method + " " + path
+		}
+	}
+
+	// only keep the schemas reachable from the operations
+	queue := []*Schema{}
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			for _, p := range op.Parameters {
+				queue = append(queue, p.Schema)
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					queue = append(queue, media.Schema)
+				}
+			}
+			for _, response := range op.Responses {
+				for _, media := range response.Content {
+					queue = append(queue, media.Schema)
+				}
+			}
+		}
+	}
+	for len(queue) > 0 {
+		schema := queue[0]
+		queue = queue[1:]
+		if schema == nil {
+			continue
+		}
+		if schema.Ref != "" {
+			name := schema.RefName()
+			if _, ok := doc.Components.Schemas[name]; ok {
+				continue
+			}
+			for original, component := range names {
+				if component == name {
+					definition := swagger.Definitions[original]
+					converted := convertSchema(&definition, names)
+					converted.Required = requiredFields(converted, optional[original])
+					doc.Components.Schemas[name] = converted
+					queue = append(queue, converted)
+				}
+			}
+			continue
+		}
+		queue = append(queue, schema.Items, schema.AdditionalProperties)
+		queue = append(queue, schema.AllOf...)
+		for _, property := range schema.Properties {
+			queue = append(queue, property)
+		}
+	}
+
+	for name, definition := range swagger.SecurityDefinitions {
+		doc.Components.SecuritySchemes[name] = SecurityScheme{
+			Type:        definition.Type,
+			Name:        definition.Name,
+			In:          definition.In,
+			Description: definition.Description,
+		}
+	}
+
+	return doc, nil
+}
+
+// versionedPaths returns the paths serving op with their operation ids: the
+// /api/v1 path is repeated for every version of the x-versions extension, the
+// ids of versions after v1 get the version as suffix, e.g getFeesV2
+func versionedPaths(path string, op *spec.Operation) (map[string]string, error) {
+	raw, ok := op.Extensions[versionsExtension]
+	if !ok {
+		return map[string]string{path: op.ID}, nil
+	}
+
+	rest, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return nil, fmt.Errorf("%s is only supported on /api/v1 routes", versionsExtension)
+	}
+	versions, ok := raw.([]any)
+	if !ok || len(versions) == 0 {
+		return nil, fmt.Errorf("%s must be a list of versions, e.g [\"v1\",\"v2\"]", versionsExtension)
+	}
+
+	paths := map[string]string{}
+	for _, v := range versions {
+		version, ok := v.(string)
+		if !ok || !strings.HasPrefix(version, "v") || len(version) < 2 {
+			return nil, fmt.Errorf("%s: invalid version %v", versionsExtension, v)
+		}
+		id := op.ID
+		if version != "v1" {
+			id += strings.ToUpper(version)
+		}
+		paths["/api/"+version+"/"+rest] = id
+	}
+
+	return paths, nil
+}
+
+// componentNames maps the swag definition names (e.g model.Web3BigInt) to
+// component names without the package (Web3BigInt)
+func componentNames(definitions spec.Definitions) (map[string]string, error) {
+	names := map[string]string{}
+	owners := map[string]string{}
+	for original := range definitions {
+		name := original[strings.LastIndex(original, ".")+1:]
+		if owner, ok := owners[name]; ok {
+			return nil, fmt.Errorf("schemas %s and %s would both be named %s", owner, original, name)
+		}
+		owners[name] = original
+		names[original] = name
+	}
+
+	return names, nil
+}
+
+func convertOperation(op *spec.Operation, names map[string]string) *Operation {
+	converted := &Operation{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Security:    op.Security,
+		Responses:   map[string]Response{},
+	}
+
+	contentTypes := op.Produces
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"application/json"}
+	}
+
+	for _, p := range op.Parameters {
+		if p.In == "body" {
+			converted.RequestBody = &RequestBody{
+				Description: p.Description,
+				Required:    p.Required,
+				Content:     map[string]MediaType{"application/json": {Schema: convertSchema(p.Schema, names)}},
+			}
+			continue
+		}
+
+		schema := &Schema{Type: p.Type, Format: p.Format, Enum: p.Enum, Default: p.Default}
+		if p.Items != nil {
+			schema.Items = &Schema{Type: p.Items.Type, Format: p.Items.Format, Enum: p.Items.Enum}
+		}
+		converted.Parameters = append(converted.Parameters, Parameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      schema,
+		})
+	}
+	sort.SliceStable(converted.Parameters, func(i, j int) bool {
+		return converted.Parameters[i].In+converted.Parameters[i].Name < converted.Parameters[j].In+converted.Parameters[j].Name
+	})
+
+	if op.Responses != nil {
+		for code, response := range op.Responses.StatusCodeResponses {
+			description := response.Description
+			if description == "" {
+				description = http.StatusText(code)
+			}
+			r := Response{Description: description}
+			if response.Schema != nil {
+				r.Content = map[string]MediaType{}
+				for _, contentType := range contentTypes {
+					r.Content[contentType] = MediaType{Schema: convertSchema(response.Schema, names)}
+				}
+			}
+			converted.Responses[fmt.Sprint(code)] = r
+		}
+	}
+
+	return converted
+}
+
+func convertSchema(schema *spec.Schema, names map[string]string) *Schema {
+	if schema == nil {
+		return nil
+	}
+
+	if ref := schema.Ref.String(); ref != "" {
+		return &Schema{Ref: "#/components/schemas/" + names[strings.TrimPrefix(ref, "#/definitions/")]}
+	}
+
+	converted := &Schema{
+		Format:      schema.Format,
+		Description: schema.Description,
+		Enum:        schema.Enum,
+		Default:     schema.Default,
+		Required:    schema.Required,
+	}
+	if len(schema.Type) > 0 {
+		converted.Type = schema.Type[0]
+	}
+	if schema.Items != nil {
+		converted.Items = convertSchema(schema.Items.Schema, names)
+	}
+	if schema.AdditionalProperties != nil {
+		converted.AdditionalProperties = convertSchema(schema.AdditionalProperties.Schema, names)
+		if converted.AdditionalProperties == nil && schema.AdditionalProperties.Allows {
+			converted.AdditionalProperties = &Schema{}
+		}
+	}
+	if len(schema.Properties) > 0 {
+		converted.Properties = map[string]*Schema{}
+		for name, property := range schema.Properties {
+			property := property
+			converted.Properties[name] = convertSchema(&property, names)
+		}
+	}
+	for i := range schema.AllOf {
+		part := convertSchema(&schema.AllOf[i], names)
+		// the fields overridden by a swag composition, e.g DataResponse{data=model.X}, are always set
+		if part.Ref == "" && part.Required == nil {
+			part.Required = sortedKeys(part.Properties)
+		}
+		converted.AllOf = append(converted.AllOf, part)
+	}
+
+	return converted
+}
+
+// requiredFields drops the omitempty fields from the required list swag marks
+// every field in
+func requiredFields(schema *Schema, optional map[string]bool) []string {
+	required := []string{}
+	for _, name := range schema.Required {
+		if !optional[name] {
+			required = append(required, name)
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+	sort.Strings(required)
+
+	return required
+}
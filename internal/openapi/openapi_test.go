@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-openapi/spec"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const root = "../.."
+
+var _ = Describe("OpenAPI", func() {
+	var doc *Document
+
+	BeforeEach(func() {
+		var err error
+		doc, err = Generate(root)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should match the committed spec and client, run make gen-openapi otherwise", func() {
+		spec, err := doc.Marshal()
+		Expect(err).NotTo(HaveOccurred())
+
+		committedSpec, err := os.ReadFile(filepath.Join(root, SpecFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(committedSpec)).To(Equal(string(spec)), "%s is stale", SpecFile)
+
+		committedClient, err := os.ReadFile(filepath.Join(root, ClientFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(committedClient)).To(Equal(string(TypeScriptClient(doc))), "%s is stale", ClientFile)
+	})
+
+	It("should document the admin operations behind the API key", func() {
+		Expect(doc.Paths).To(HaveKey("/api/v1/admin/jobs/{name}/run"))
+		Expect(doc.Paths["/api/v1/admin/chaos/{target}"]["put"].Security).To(Equal([]map[string][]string{{"ApiKeyAuth": {}}}))
+		Expect(doc.Components.Schemas).To(HaveKey("JobStatus"))
+		Expect(doc.Components.Schemas).To(HaveKey("ChaosFault"))
+	})
+
+	It("should document the public routes under every version", func() {
+		Expect(doc.Paths["/api/v1/fees"]["get"].OperationID).To(Equal("getFeeSchedule"))
+		Expect(doc.Paths["/api/v2/fees"]["get"].OperationID).To(Equal("getFeeScheduleV2"))
+		Expect(doc.Paths).NotTo(HaveKey("/api/v2/admin/jobs"))
+	})
+
+	It("should type the error responses of every client operation", func() {
+		client := string(TypeScriptClient(doc))
+
+		Expect(client).To(ContainSubstring("export type ReadinessError = IcyBackendError<503, HealthStatus>;"))
+		Expect(client).To(ContainSubstring("export type TriggerJobError = IcyBackendError<401, ErrorResponse> | IcyBackendError<403, ErrorResponse> | IcyBackendError<404, ErrorResponse> | IcyBackendError<409, ErrorResponse>;"))
+		Expect(client).To(ContainSubstring("getFeeScheduleV2(): Promise<DataResponse & { data: FeeSchedule }>"))
+		Expect(client).NotTo(ContainSubstring("LivenessError"))
+	})
+
+	It("should only require the fields that are not omitempty", func() {
+		Expect(doc.Components.Schemas["ApiError"].Required).To(Equal([]string{"field", "msg"}))
+	})
+
+	Describe("#convert", func() {
+		operation := func(id string) *spec.Operation {
+			op := spec.NewOperation(id)
+			op.RespondsWith(200, spec.NewResponse().WithSchema(spec.RefSchema("#/definitions/model.Item")))
+			return op
+		}
+		newSwagger := func(paths map[string]spec.PathItem) *spec.Swagger {
+			return &spec.Swagger{SwaggerProps: spec.SwaggerProps{
+				Info:  &spec.Info{},
+				Paths: &spec.Paths{Paths: paths},
+				Definitions: spec.Definitions{
+					"model.Item": *spec.MapProperty(spec.StringProperty()).
+						SetProperty("tags", *spec.ArrayProperty(spec.StringProperty())).
+						WithRequired("tags"),
+					"model.Unused": *spec.StringProperty(),
+				},
+			}}
+		}
+
+		It("should turn a body parameter into a request body", func() {
+			op := operation("setItem")
+			op.AddParam(spec.BodyParam("item", spec.RefSchema("#/definitions/model.Item")).AsRequired())
+			op.AddParam(spec.PathParam("name").Typed("string", ""))
+
+			doc, err := convert(newSwagger(map[string]spec.PathItem{"/items/{name}": {PathItemProps: spec.PathItemProps{Put: op}}}), nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			converted := doc.Paths["/items/{name}"]["put"]
+			Expect(converted.RequestBody.Required).To(BeTrue())
+			Expect(converted.RequestBody.Content["application/json"].Schema.Ref).To(Equal("#/components/schemas/Item"))
+			Expect(converted.Parameters).To(Equal([]Parameter{{Name: "name", In: "path", Required: true, Schema: &Schema{Type: "string"}}}))
+		})
+
+		It("should strip the package from schema names, keep free form maps and drop unused schemas", func() {
+			doc, err := convert(newSwagger(map[string]spec.PathItem{"/items": {PathItemProps: spec.PathItemProps{Get: operation("getItem")}}}), nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Components.Schemas).To(HaveLen(1))
+			item := doc.Components.Schemas["Item"]
+			Expect(item.Type).To(Equal("object"))
+			Expect(item.AdditionalProperties).To(Equal(&Schema{Type: "string"}))
+			Expect(item.Properties["tags"].Items).To(Equal(&Schema{Type: "string"}))
+			Expect(item.Required).To(Equal([]string{"tags"}))
+		})
+
+		It("should drop omitempty fields from the required ones", func() {
+			doc, err := convert(newSwagger(map[string]spec.PathItem{"/items": {PathItemProps: spec.PathItemProps{Get: operation("getItem")}}}),
+				map[string]map[string]bool{"model.Item": {"tags": true}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Components.Schemas["Item"].Required).To(BeNil())
+		})
+
+		It("should require the fields overridden by a composition", func() {
+			op := spec.NewOperation("getWrapped")
+			op.RespondsWith(200, spec.NewResponse().WithSchema(spec.ComposedSchema(
+				*spec.RefSchema("#/definitions/model.Item"),
+				*new(spec.Schema).Typed("object", "").SetProperty("data", *spec.StringProperty()),
+			)))
+
+			doc, err := convert(newSwagger(map[string]spec.PathItem{"/wrapped": {PathItemProps: spec.PathItemProps{Get: op}}}), nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			allOf := doc.Paths["/wrapped"]["get"].Responses["200"].Content["application/json"].Schema.AllOf
+			Expect(allOf).To(HaveLen(2))
+			Expect(allOf[0].Ref).To(Equal("#/components/schemas/Item"))
+			Expect(allOf[1].Required).To(Equal([]string{"data"}))
+		})
+
+		It("should repeat a v1 operation under every listed version", func() {
+			op := operation("getItem")
+			op.AddExtension(versionsExtension, []any{"v1", "v2"})
+
+			doc, err := convert(newSwagger(map[string]spec.PathItem{"/api/v1/items": {PathItemProps: spec.PathItemProps{Get: op}}}), nil)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(doc.Paths["/api/v1/items"]["get"].OperationID).To(Equal("getItem"))
+			Expect(doc.Paths["/api/v2/items"]["get"].OperationID).To(Equal("getItemV2"))
+		})
+
+		It("should reject missing and duplicated operation ids", func() {
+			_, err := convert(newSwagger(map[string]spec.PathItem{"/items": {PathItemProps: spec.PathItemProps{Get: operation("")}}}), nil)
+			Expect(err).To(MatchError(ContainSubstring("missing @id")))
+
+			op := operation("getItem")
+			op.AddExtension(versionsExtension, []any{"v1", "v2"})
+			_, err = convert(newSwagger(map[string]spec.PathItem{
+				"/api/v1/items": {PathItemProps: spec.PathItemProps{Get: op}},
+				"/api/v2/items": {PathItemProps: spec.PathItemProps{Post: operation("getItemV2")}},
+			}), nil)
+			Expect(err).To(MatchError(ContainSubstring("getItemV2 is already used")))
+		})
+
+		It("should reject schemas which would share a name", func() {
+			swagger := newSwagger(map[string]spec.PathItem{})
+			swagger.Definitions["view.Item"] = *spec.StringProperty()
+
+			_, err := convert(swagger, nil)
+			Expect(err).To(MatchError(ContainSubstring("would both be named Item")))
+		})
+	})
+})
@@ -0,0 +1,304 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const clientRuntime = `export interface ClientOptions {
+  // e.g https://backend.icy.so
+  baseUrl: string;
+  // optional, switches rate limiting to per key
+  apiKey?: string;
+  fetch?: typeof fetch;
+}
+
+// thrown on a non-2xx response, each operation exports the union of its
+// documented errors, e.g ReadinessError, to narrow the body on the status
+export class IcyBackendError<Status extends number = number, Body = unknown> extends Error {
+  constructor(
+    readonly status: Status,
+    readonly body: Body | undefined,
+  ) {
+    super((body as { message?: string } | undefined)?.message ?? ` + "`request failed with status ${status}`" + `);
+  }
+}
+
+type Query = Record<string, string | number | boolean | undefined>;
+
+export class IcyBackendClient {
+  constructor(private readonly options: ClientOptions) {}
+
+  private async request<T>(method: string, path: string, query?: Query, body?: unknown): Promise<T> {
+    const search = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined) search.set(key, String(value));
+    }
+    const url = this.options.baseUrl.replace(/\/$/, "") + path + (search.size > 0 ? "?" + search : "");
+
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (this.options.apiKey) headers["X-API-Key"] = this.options.apiKey;
+    if (body !== undefined) headers["Content-Type"] = "application/json";
+
+    const res = await (this.options.fetch ?? fetch)(url, {
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    const payload = await res.json().catch(() => undefined);
+    if (!res.ok) {
+      throw new IcyBackendError(res.status, payload);
+    }
+    return payload as T;
+  }
+`
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// TypeScriptClient returns a fetch based TypeScript client for the JSON
+// operations of doc, with an interface per schema
+func TypeScriptClient(doc *Document) []byte {
+	b := &strings.Builder{}
+	b.WriteString("// Code generated by cmd/openapi from " + SpecFile + ". DO NOT EDIT.\n\n")
+
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		schema := doc.Components.Schemas[name]
+		writeComment(b, "", schema.Description)
+		if len(schema.Properties) == 0 || len(schema.AllOf) > 0 {
+			fmt.Fprintf(b, "export type %s = %s;\n\n", name, tsType(schema))
+			continue
+		}
+		fmt.Fprintf(b, "export interface %s %s\n\n", name, tsObject(schema, ""))
+	}
+
+	b.WriteString(clientRuntime)
+
+	// the error types are declared after the client class, they can't live in it
+	errorTypes := &strings.Builder{}
+	for _, path := range sortedKeys(doc.Paths) {
+		for _, method := range sortedKeys(doc.Paths[path]) {
+			op := doc.Paths[path][method]
+			result, ok := successType(op)
+			if !ok {
+				// e.g the server-sent events stream, use an EventSource instead
+				continue
+			}
+			errorType := writeErrorType(errorTypes, op)
+			writeOperation(b, path, method, op, result, errorType)
+		}
+	}
+
+	b.WriteString("}\n")
+	b.WriteString(errorTypes.String())
+
+	return []byte(b.String())
+}
+
+// writeErrorType declares the union of the documented non-2xx responses of op
+// and returns its name, or "" when op documents none
+func writeErrorType(b *strings.Builder, op *Operation) string {
+	errors := []string{}
+	for _, code := range sortedKeys(op.Responses) {
+		if strings.HasPrefix(code, "2") {
+			continue
+		}
+		body := "undefined"
+		if media, ok := op.Responses[code].Content["application/json"]; ok {
+			body = tsType(media.Schema)
+		}
+		errors = append(errors, fmt.Sprintf("IcyBackendError<%s, %s>", code, body))
+	}
+	if len(errors) == 0 {
+		return ""
+	}
+
+	name := strings.ToUpper(op.OperationID[:1]) + op.OperationID[1:] + "Error"
+	fmt.Fprintf(b, "\nexport type %s = %s;\n", name, strings.Join(errors, " | "))
+	return name
+}
+
+func writeOperation(b *strings.Builder, path, method string, op *Operation, result, errorType string) {
+	args := []string{}
+	pathArgs := []string{}
+	queryArgs := []string{}
+	queryRequired := false
+	for _, p := range op.Parameters {
+		field := fmt.Sprintf("%s%s: %s", quoteKey(p.Name), optionalMark(!p.Required), tsType(p.Schema))
+		switch p.In {
+		case "path":
+			pathArgs = append(pathArgs, field)
+		case "query":
+			queryArgs = append(queryArgs, field)
+			queryRequired = queryRequired || p.Required
+		}
+	}
+	if len(pathArgs) > 0 {
+		args = append(args, fmt.Sprintf("params: { %s }", strings.Join(pathArgs, "; ")))
+	}
+	if len(queryArgs) > 0 {
+		args = append(args, fmt.Sprintf("query%s: { %s }", optionalMark(!queryRequired), strings.Join(queryArgs, "; ")))
+	}
+	body := "undefined"
+	if op.RequestBody != nil {
+		args = append(args, fmt.Sprintf("body: %s", tsType(op.RequestBody.Content["application/json"].Schema)))
+		body = "body"
+	}
+
+	urlPath := "\"" + path + "\""
+	if len(pathArgs) > 0 {
+		urlPath = "`" + pathParam.ReplaceAllString(path, "$${encodeURIComponent(String(params.$1))}") + "`"
+	}
+	query := "undefined"
+	if len(queryArgs) > 0 {
+		query = "query"
+	}
+
+	b.WriteString("\n")
+	comment := op.Summary
+	if op.Description != "" {
+		comment = op.Description
+	}
+	if errorType != "" {
+		comment = strings.TrimSpace(comment + " @throws {" + errorType + "}")
+	}
+	writeComment(b, "  ", comment)
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", op.OperationID, strings.Join(args, ", "), result)
+	fmt.Fprintf(b, "    return this.request(%q, %s, %s, %s);\n", strings.ToUpper(method), urlPath, query, body)
+	b.WriteString("  }\n")
+}
+
+// successType returns the type of the first 2xx JSON response
+func successType(op *Operation) (string, bool) {
+	codes := sortedKeys(op.Responses)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		response := op.Responses[code]
+		if len(response.Content) == 0 {
+			return "void", true
+		}
+		media, ok := response.Content["application/json"]
+		if !ok {
+			return "", false
+		}
+		return tsType(media.Schema), true
+	}
+	return "void", true
+}
+
+func tsType(schema *Schema) string {
+	if schema == nil {
+		return "unknown"
+	}
+	if schema.Ref != "" {
+		return schema.RefName()
+	}
+	if len(schema.AllOf) > 0 {
+		parts := []string{}
+		for _, part := range schema.AllOf {
+			parts = append(parts, tsType(part))
+		}
+		return strings.Join(parts, " & ")
+	}
+	if len(schema.Enum) > 0 {
+		values := []string{}
+		for _, value := range schema.Enum {
+			values = append(values, fmt.Sprintf("%q", fmt.Sprint(value)))
+		}
+		if schema.Type != "string" {
+			values = values[:0]
+			for _, value := range schema.Enum {
+				values = append(values, fmt.Sprint(value))
+			}
+		}
+		return strings.Join(values, " | ")
+	}
+
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := tsType(schema.Items)
+		if strings.ContainsAny(item, "|&") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if len(schema.Properties) > 0 {
+			return tsInlineObject(schema)
+		}
+		if schema.AdditionalProperties != nil {
+			return "Record<string, " + tsType(schema.AdditionalProperties) + ">"
+		}
+	}
+	return "unknown"
+}
+
+func tsObject(schema *Schema, indent string) string {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	b := &strings.Builder{}
+	b.WriteString("{\n")
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+		writeComment(b, indent+"  ", property.Description)
+		fmt.Fprintf(b, "%s  %s%s: %s;\n", indent, quoteKey(name), optionalMark(!required[name]), tsType(property))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func tsInlineObject(schema *Schema) string {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	fields := []string{}
+	for _, name := range sortedKeys(schema.Properties) {
+		fields = append(fields, fmt.Sprintf("%s%s: %s", quoteKey(name), optionalMark(!required[name]), tsType(schema.Properties[name])))
+	}
+	return "{ " + strings.Join(fields, "; ") + " }"
+}
+
+func writeComment(b *strings.Builder, indent, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(b, "%s/** %s */\n", indent, strings.ReplaceAll(text, "*/", "* /"))
+}
+
+func optionalMark(optional bool) string {
+	if optional {
+		return "?"
+	}
+	return ""
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func quoteKey(name string) string {
+	if identifier.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
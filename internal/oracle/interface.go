@@ -1,18 +1,43 @@
 package oracle
 
-import "github.com/dwarvesf/icy-backend/internal/model"
+import (
+	"context"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
 
 type IOracle interface {
 	// GetCirculatedICY returns the number of circulated ICY
 	// excludes the ICY that is locked in the treasury
 	GetCirculatedICY() (*model.Web3BigInt, error)
 
+	// GetCachedCirculatedICY returns the cached number of circulated ICY
+	GetCachedCirculatedICY() (*model.Web3BigInt, error)
+
 	// GetBTCSupply returns the total supply of BTC in treasury wallet
 	GetBTCSupply() (*model.Web3BigInt, error)
 
+	// GetCachedBTCSupply returns the cached total supply of BTC in treasury wallet
+	GetCachedBTCSupply() (*model.Web3BigInt, error)
+
 	// GetRealtimeICYBTC returns the realtime ICY/BTC price
 	GetRealtimeICYBTC() (*model.Web3BigInt, error)
 
 	// GetCachedRealtimeICYBTC returns the cached realtime ICY/BTC price
 	GetCachedRealtimeICYBTC() (*model.Web3BigInt, error)
+
+	// GetICYBTCHistory returns the OHLC of the recorded ICY/BTC prices in [from, to)
+	GetICYBTCHistory(ctx context.Context, from, to time.Time, interval model.RateInterval) ([]model.IcyBtcRateCandle, error)
+
+	// SetCacheTTL changes the TTL of the cached values, zero disables caching
+	SetCacheTTL(ttl time.Duration)
+
+	// RefreshCache refreshes every cached value of this replica, it runs as a
+	// scheduled job
+	RefreshCache(ctx context.Context) error
+
+	// RecordICYBTCRate records the ICY/BTC price for the price history, it runs
+	// as a scheduled job on a single replica
+	RecordICYBTCRate(ctx context.Context) error
 }
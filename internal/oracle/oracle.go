@@ -1,35 +1,59 @@
 package oracle
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 
 	"github.com/dwarvesf/icy-backend/internal/btcrpc"
 	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/store"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
 )
 
+type cachedValue struct {
+	value     *model.Web3BigInt
+	updatedAt time.Time
+}
+
 type IcyOracle struct {
-	mux *sync.Mutex
+	mux      *sync.Mutex
+	cacheTTL time.Duration
 
-	cachedICYBTC *model.Web3BigInt
+	// concurrent cache misses share a single fetch
+	fetches singleflight.Group
+
+	cachedICYBTC        cachedValue
+	cachedCirculatedICY cachedValue
+	cachedBTCSupply     cachedValue
 
 	appConfig *config.AppConfig
 	logger    *logger.Logger
 	btcRpc    btcrpc.IBtcRpc
+	db        *gorm.DB
+	readDB    *gorm.DB
+	store     *store.Store
 }
 
 // TODO: add other smaller packages if needed, e.g btcRPC or baseRPC
-func New(appConfig *config.AppConfig, logger *logger.Logger, btcRpc btcrpc.IBtcRpc) IOracle {
+func New(appConfig *config.AppConfig, logger *logger.Logger, btcRpc btcrpc.IBtcRpc, db, readDB *gorm.DB, store *store.Store) IOracle {
 	o := &IcyOracle{
 		mux:       &sync.Mutex{},
+		cacheTTL:  appConfig.Oracle.CacheTTL,
 		appConfig: appConfig,
 		logger:    logger,
 		btcRpc:    btcRpc,
+		db:        db,
+		readDB:    readDB,
+		store:     store,
 	}
 
-	// go o.startUpdateCachedRealtimeICYBTC()
-
 	return o
 }
 
@@ -41,6 +65,10 @@ func (o *IcyOracle) GetCirculatedICY() (*model.Web3BigInt, error) {
 	return &mockData, nil
 }
 
+func (o *IcyOracle) GetCachedCirculatedICY() (*model.Web3BigInt, error) {
+	return o.getCached("circulated-icy", &o.cachedCirculatedICY, o.GetCirculatedICY)
+}
+
 func (o *IcyOracle) GetBTCSupply() (*model.Web3BigInt, error) {
 	mockData := model.Web3BigInt{
 		Value:   "100000000000000000000000000",
@@ -49,6 +77,10 @@ func (o *IcyOracle) GetBTCSupply() (*model.Web3BigInt, error) {
 	return &mockData, nil
 }
 
+func (o *IcyOracle) GetCachedBTCSupply() (*model.Web3BigInt, error) {
+	return o.getCached("btc-supply", &o.cachedBTCSupply, o.GetBTCSupply)
+}
+
 func (o *IcyOracle) GetRealtimeICYBTC() (*model.Web3BigInt, error) {
 	mockData := model.Web3BigInt{
 		Value:   "1500000000000000000",
@@ -58,18 +90,84 @@ func (o *IcyOracle) GetRealtimeICYBTC() (*model.Web3BigInt, error) {
 }
 
 func (o *IcyOracle) GetCachedRealtimeICYBTC() (*model.Web3BigInt, error) {
+	return o.getCached("icy-btc", &o.cachedICYBTC, o.GetRealtimeICYBTC)
+}
+
+func (o *IcyOracle) GetICYBTCHistory(ctx context.Context, from, to time.Time, interval model.RateInterval) ([]model.IcyBtcRateCandle, error) {
+	return o.store.IcyBtcRate.ListCandles(ctx, o.readDB, from, to, interval)
+}
+
+func (o *IcyOracle) SetCacheTTL(ttl time.Duration) {
 	o.mux.Lock()
 	defer o.mux.Unlock()
-	mockData := model.Web3BigInt{
-		Value:   "1500000000000000000",
-		Decimal: 18,
-	}
-	return &mockData, nil
+	o.cacheTTL = ttl
 }
 
-func (o *IcyOracle) refreshCachedRealtimeICYBTC() {
+// getCached returns the cached value while it is fresh, otherwise it fetches
+// and caches a new one
+func (o *IcyOracle) getCached(key string, cached *cachedValue, fetch func() (*model.Web3BigInt, error)) (*model.Web3BigInt, error) {
 	o.mux.Lock()
-	defer o.mux.Unlock()
+	if cached.value != nil && time.Since(cached.updatedAt) < o.cacheTTL {
+		value := cached.value
+		o.mux.Unlock()
+		return value, nil
+	}
+	o.mux.Unlock()
+
+	return o.refreshCached(key, cached, fetch)
+}
+
+func (o *IcyOracle) refreshCached(key string, cached *cachedValue, fetch func() (*model.Web3BigInt, error)) (*model.Web3BigInt, error) {
+	value, err, _ := o.fetches.Do(key, func() (any, error) {
+		value, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		o.mux.Lock()
+		defer o.mux.Unlock()
+		cached.value = value
+		cached.updatedAt = time.Now()
+
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*model.Web3BigInt), nil
+}
+
+// RefreshCache refreshes every cached value, so requests are served from the
+// cache as long as the refresh keeps succeeding. The cache is in memory, every
+// replica refreshes its own
+func (o *IcyOracle) RefreshCache(ctx context.Context) error {
+	errs := []error{}
+	if _, err := o.refreshCached("circulated-icy", &o.cachedCirculatedICY, o.GetCirculatedICY); err != nil {
+		errs = append(errs, fmt.Errorf("circulated ICY: %w", err))
+	}
+	if _, err := o.refreshCached("btc-supply", &o.cachedBTCSupply, o.GetBTCSupply); err != nil {
+		errs = append(errs, fmt.Errorf("BTC supply: %w", err))
+	}
+	if _, err := o.refreshCached("icy-btc", &o.cachedICYBTC, o.GetRealtimeICYBTC); err != nil {
+		errs = append(errs, fmt.Errorf("ICY/BTC price: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// RecordICYBTCRate records the ICY/BTC price for the price history. Prices are
+// only recorded here, whatever the cache TTL, so reads never write to the database
+func (o *IcyOracle) RecordICYBTCRate(ctx context.Context) error {
+	icyBtc, err := o.getCached("icy-btc", &o.cachedICYBTC, o.GetRealtimeICYBTC)
+	if err != nil {
+		return fmt.Errorf("ICY/BTC price: %w", err)
+	}
+
+	rate := &model.IcyBtcRate{Value: icyBtc.Value, Decimal: icyBtc.Decimal}
+	if err := o.store.IcyBtcRate.Create(ctx, o.db, rate); err != nil {
+		return fmt.Errorf("record ICY/BTC price: %w", err)
+	}
 
-	// o.cachedICYBTC = price
+	return nil
 }
@@ -0,0 +1,72 @@
+package oracle
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/btcrpc"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/store"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+var _ = Describe("Oracle", func() {
+	var o *IcyOracle
+
+	BeforeEach(func() {
+		appConfig := &config.AppConfig{}
+		log := logger.New(environments.Test)
+		// no database, a read which records a price would panic
+		o = New(appConfig, log, btcrpc.New(appConfig, log), nil, nil, store.New()).(*IcyOracle)
+	})
+
+	Describe("#GetCachedRealtimeICYBTC", func() {
+		It("should not record prices when caching is disabled", func() {
+			for i := 0; i < 3; i++ {
+				value, err := o.GetCachedRealtimeICYBTC()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value.Value).NotTo(BeEmpty())
+			}
+		})
+
+		It("should share a single fetch between concurrent misses", func() {
+			var (
+				mux     sync.Mutex
+				fetches int
+				release = make(chan struct{})
+				started sync.WaitGroup
+				wg      sync.WaitGroup
+			)
+			fetch := func() (*model.Web3BigInt, error) {
+				mux.Lock()
+				fetches++
+				mux.Unlock()
+				<-release
+				return &model.Web3BigInt{Value: "1", Decimal: 18}, nil
+			}
+
+			cached := &cachedValue{}
+			for i := 0; i < 5; i++ {
+				wg.Add(1)
+				started.Add(1)
+				go func() {
+					defer wg.Done()
+					started.Done()
+					_, _ = o.getCached("test", cached, fetch)
+				}()
+			}
+			started.Wait()
+			// let every goroutine join the in-flight fetch before it completes
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			Expect(fetches).To(Equal(1))
+		})
+	})
+})
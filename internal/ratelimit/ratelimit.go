@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is how long a bucket is kept after its last request, a bucket
+// idle that long is full again anyway so dropping it changes nothing
+const idleBucketTTL = 10 * time.Minute
+
+// defaultMaxBuckets bounds the memory used by the buckets, e.g when a client
+// spreads requests over many addresses
+const defaultMaxBuckets = 100_000
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// Limiter is an in-memory token bucket limiter keyed by client
+type Limiter struct {
+	mux        *sync.Mutex
+	buckets    map[string]*bucket
+	maxBuckets int
+	lastSweep  time.Time
+
+	rate  float64
+	burst float64
+	now   func() time.Time
+}
+
+// New returns a limiter allowing rate requests per second per key, with bursts
+// up to burst requests
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		mux:        &sync.Mutex{},
+		buckets:    map[string]*bucket{},
+		maxBuckets: defaultMaxBuckets,
+		lastSweep:  time.Now(),
+		rate:       rate,
+		burst:      float64(burst),
+		now:        time.Now,
+	}
+}
+
+// Allow consumes a token from the bucket of key. When no token is left it
+// returns false and how long to wait before the next token is available
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+	l.sweep(now, false)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.maxBuckets {
+			l.sweep(now, true)
+			l.evictOldest()
+		}
+		b = &bucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.lastSeen).Seconds()*l.rate)
+	b.lastSeen = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	retryAfter := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, retryAfter
+}
+
+// SetRate changes the rate and burst, existing buckets keep their tokens up to
+// the new burst
+func (l *Limiter) SetRate(rate float64, burst int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.rate = rate
+	l.burst = float64(burst)
+}
+
+// sweep drops the idle buckets, at most once per idleBucketTTL unless forced
+func (l *Limiter) sweep(now time.Time, force bool) {
+	if !force && now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// evictOldest drops the least recently seen bucket while the limiter is full
+func (l *Limiter) evictOldest() {
+	for len(l.buckets) > 0 && len(l.buckets) >= l.maxBuckets {
+		oldestKey := ""
+		var oldest time.Time
+		for key, b := range l.buckets {
+			if oldestKey == "" || b.lastSeen.Before(oldest) {
+				oldestKey, oldest = key, b.lastSeen
+			}
+		}
+		delete(l.buckets, oldestKey)
+	}
+}
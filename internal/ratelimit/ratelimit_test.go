@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Limiter", func() {
+	var (
+		limiter *Limiter
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		limiter = New(1, 2)
+		limiter.now = func() time.Time { return now }
+	})
+
+	Describe("#SetRate", func() {
+		It("should apply the new burst", func() {
+			limiter.SetRate(1, 1)
+
+			allowed, _ := limiter.Allow("ip:1")
+			Expect(allowed).To(BeTrue())
+			allowed, _ = limiter.Allow("ip:1")
+			Expect(allowed).To(BeFalse())
+		})
+	})
+
+	Describe("#Allow", func() {
+		It("should allow bursts up to the burst size", func() {
+			allowed, _ := limiter.Allow("ip:1")
+			Expect(allowed).To(BeTrue())
+			allowed, _ = limiter.Allow("ip:1")
+			Expect(allowed).To(BeTrue())
+
+			allowed, retryAfter := limiter.Allow("ip:1")
+			Expect(allowed).To(BeFalse())
+			Expect(retryAfter).To(Equal(time.Second))
+		})
+
+		It("should refill tokens over time", func() {
+			limiter.Allow("ip:1")
+			limiter.Allow("ip:1")
+
+			now = now.Add(500 * time.Millisecond)
+			allowed, retryAfter := limiter.Allow("ip:1")
+			Expect(allowed).To(BeFalse())
+			Expect(retryAfter).To(Equal(500 * time.Millisecond))
+
+			now = now.Add(500 * time.Millisecond)
+			allowed, _ = limiter.Allow("ip:1")
+			Expect(allowed).To(BeTrue())
+		})
+
+		It("should keep separate buckets per key", func() {
+			limiter.Allow("ip:1")
+			limiter.Allow("ip:1")
+
+			allowed, _ := limiter.Allow("key:1")
+			Expect(allowed).To(BeTrue())
+		})
+
+		It("should drop idle buckets", func() {
+			limiter.Allow("ip:1")
+
+			now = now.Add(2 * idleBucketTTL)
+			limiter.Allow("ip:2")
+			Expect(limiter.buckets).NotTo(HaveKey("ip:1"))
+		})
+
+		It("should evict the least recently seen bucket once full", func() {
+			limiter.maxBuckets = 2
+			limiter.Allow("ip:1")
+			now = now.Add(time.Second)
+			limiter.Allow("ip:2")
+			now = now.Add(time.Second)
+			limiter.Allow("ip:3")
+
+			Expect(limiter.buckets).To(HaveLen(2))
+			Expect(limiter.buckets).NotTo(HaveKey("ip:1"))
+		})
+	})
+})
@@ -0,0 +1,23 @@
+package scheduler
+
+import "github.com/dwarvesf/icy-backend/internal/model"
+
+type IScheduler interface {
+	// Register adds a job, it must be called before Start
+	Register(job Job) error
+
+	// Start runs every registered job on its schedule
+	Start()
+
+	// Trigger runs a job now in background, unless it is already running
+	Trigger(name string) error
+
+	// ListJobs returns the status of every job, sorted by name
+	ListJobs() []model.JobStatus
+
+	// GetJob returns the status of a job
+	GetJob(name string) (*model.JobStatus, error)
+
+	// Stop stops scheduling and waits for the running jobs to return
+	Stop()
+}
@@ -0,0 +1,318 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/cron"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+// defaultStallAfter is used for jobs without a StallAfter
+const defaultStallAfter = 10 * time.Minute
+
+// defaultLeaseTTL is how long a job lease lasts without a renewal, so a crashed
+// replica's job is taken over after at most that long. Running jobs renew it
+// every third of the TTL
+const defaultLeaseTTL = time.Minute
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+type Job struct {
+	Name string
+	// Schedule is a cron expression, see cron.Parse
+	Schedule string
+	// Jitter delays every scheduled run by a random duration up to Jitter, so
+	// replicas don't hit the same dependencies at the same instant
+	Jitter time.Duration
+	// StallAfter is how long a run can take before the job is reported as stalled
+	StallAfter time.Duration
+	// Local jobs run on every replica without a lease, e.g to refresh an in-memory cache
+	Local bool
+	// Run must return once ctx is done, which happens on shutdown or when the
+	// lease of the run is lost
+	Run func(ctx context.Context) error
+}
+
+// Leaser makes jobs single-flight across replicas and runs every scheduled tick once
+type Leaser interface {
+	// AcquireLease takes the lease of the job name for ttl if it is free, and
+	// claims tick unless it is zero. It is refused when tick, or a later tick,
+	// was already claimed
+	AcquireLease(ctx context.Context, name string, tick time.Time, ttl time.Duration) (lease Lease, acquired bool, err error)
+}
+
+// Lease is held by the replica running a job
+type Lease interface {
+	// Renew extends the lease by its ttl, it fails once the lease expired or
+	// was taken over
+	Renew(ctx context.Context) error
+
+	// Release frees the lease, it must be called once the run is done
+	Release()
+}
+
+type scheduledJob struct {
+	Job
+	schedule *cron.Schedule
+	running  atomic.Bool
+
+	// status is guarded by the scheduler mutex
+	status model.JobStatus
+}
+
+type Scheduler struct {
+	mux  *sync.Mutex
+	jobs map[string]*scheduledJob
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     *sync.WaitGroup
+
+	leaser   Leaser
+	leaseTTL time.Duration
+	logger   *logger.Logger
+}
+
+// New returns a scheduler, leaser may be nil when a single replica runs the jobs
+func New(logger *logger.Logger, leaser Leaser) IScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		mux:      &sync.Mutex{},
+		jobs:     map[string]*scheduledJob{},
+		ctx:      ctx,
+		cancel:   cancel,
+		wg:       &sync.WaitGroup{},
+		leaser:   leaser,
+		leaseTTL: defaultLeaseTTL,
+		logger:   logger,
+	}
+}
+
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := cron.Parse(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	if job.StallAfter <= 0 {
+		job.StallAfter = defaultStallAfter
+	}
+	s.jobs[job.Name] = &scheduledJob{
+		Job:      job,
+		schedule: schedule,
+		status:   model.JobStatus{Name: job.Name, Schedule: job.Schedule},
+	}
+
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(job)
+	}
+}
+
+func (s *Scheduler) Trigger(name string) error {
+	s.mux.Lock()
+	job, ok := s.jobs[name]
+	s.mux.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	if !job.running.CompareAndSwap(false, true) {
+		return ErrJobRunning
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(job, "manual", time.Time{})
+	}()
+
+	return nil
+}
+
+func (s *Scheduler) ListJobs() []model.JobStatus {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	statuses := make([]model.JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, s.statusOf(job))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses
+}
+
+func (s *Scheduler) GetJob(name string) (*model.JobStatus, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	status := s.statusOf(job)
+
+	return &status, nil
+}
+
+// statusOf must be called with the mutex held
+func (s *Scheduler) statusOf(job *scheduledJob) model.JobStatus {
+	status := job.status
+	status.Running = job.running.Load()
+	status.Stalled = status.Running && status.LastStartedAt != nil && time.Since(*status.LastStartedAt) > job.StallAfter
+
+	return status
+}
+
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(job *scheduledJob) {
+	defer s.wg.Done()
+
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Error("[scheduler] job schedule never fires", map[string]string{"job": job.Name})
+			return
+		}
+		s.mux.Lock()
+		job.status.NextRunAt = &next
+		s.mux.Unlock()
+
+		delay := time.Until(next)
+		if job.Jitter > 0 {
+			delay += rand.N(job.Jitter)
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		// skip this run rather than pile up behind a slow one
+		if !job.running.CompareAndSwap(false, true) {
+			s.logger.Info("[scheduler] skipping job, previous run is still in progress", map[string]string{"job": job.Name})
+			continue
+		}
+		s.execute(job, "schedule", next)
+	}
+}
+
+// execute runs a job which has been marked running and releases it. tick is
+// the scheduled time of the run, zero for manual runs
+func (s *Scheduler) execute(job *scheduledJob, trigger string, tick time.Time) {
+	defer job.running.Store(false)
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	if s.leaser != nil && !job.Local {
+		lease, acquired, err := s.leaser.AcquireLease(ctx, job.Name, tick, s.leaseTTL)
+		if err != nil {
+			s.logger.Error("[scheduler] failed to acquire job lease", map[string]string{"job": job.Name, "error": err.Error()})
+			return
+		}
+		if !acquired {
+			s.mux.Lock()
+			job.status.LockSkips++
+			s.mux.Unlock()
+			s.logger.Debug("[scheduler] skipping job, another replica is running it or ran this tick", map[string]string{"job": job.Name})
+			return
+		}
+		defer lease.Release()
+
+		heartbeatDone := make(chan struct{})
+		defer func() { <-heartbeatDone }()
+		go func() {
+			defer close(heartbeatDone)
+			s.heartbeat(ctx, cancel, job, lease)
+		}()
+		// stop the heartbeat once the run returns, before releasing the lease
+		defer cancel()
+	}
+
+	start := time.Now()
+	s.mux.Lock()
+	job.status.LastStartedAt = &start
+	s.mux.Unlock()
+
+	err := job.Run(ctx)
+
+	finish := time.Now()
+	s.mux.Lock()
+	job.status.LastFinishedAt = &finish
+	job.status.LastDurationMs = finish.Sub(start).Milliseconds()
+	job.status.LastError = ""
+	if err != nil {
+		job.status.LastError = err.Error()
+		job.status.ConsecutiveFailures++
+	} else {
+		job.status.LastSucceededAt = &finish
+		job.status.ConsecutiveFailures = 0
+	}
+	s.mux.Unlock()
+
+	fields := map[string]string{
+		"job":      job.Name,
+		"trigger":  trigger,
+		"duration": finish.Sub(start).String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		s.logger.Error("[scheduler] job failed", fields)
+		return
+	}
+
+	s.logger.Debug("[scheduler] job completed", fields)
+}
+
+// heartbeat renews the lease until ctx is done. The run is cancelled when a
+// renewal fails, another replica may take the job over once the lease expired
+func (s *Scheduler) heartbeat(ctx context.Context, cancel context.CancelFunc, job *scheduledJob, lease Lease) {
+	ticker := time.NewTicker(s.leaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := lease.Renew(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				s.logger.Error("[scheduler] lost job lease, cancelling the run", map[string]string{"job": job.Name, "error": err.Error()})
+				cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+var _ = Describe("Scheduler", func() {
+	var s IScheduler
+
+	BeforeEach(func() {
+		s = New(logger.New(environments.Test), nil)
+	})
+
+	AfterEach(func() {
+		s.Stop()
+	})
+
+	It("should reject invalid schedules and duplicated jobs", func() {
+		noop := func(context.Context) error { return nil }
+
+		Expect(s.Register(Job{Name: "a", Schedule: "bad", Run: noop})).NotTo(Succeed())
+		Expect(s.Register(Job{Name: "a", Schedule: "* * * * *", Run: noop})).To(Succeed())
+		Expect(s.Register(Job{Name: "a", Schedule: "* * * * *", Run: noop})).NotTo(Succeed())
+	})
+
+	It("should not run a triggered job twice at once", func() {
+		release := make(chan struct{})
+		runs := make(chan struct{}, 2)
+		Expect(s.Register(Job{Name: "slow", Schedule: "@yearly", Run: func(context.Context) error {
+			runs <- struct{}{}
+			<-release
+			return nil
+		}})).To(Succeed())
+
+		Expect(s.Trigger("slow")).To(Succeed())
+		Eventually(runs).Should(Receive())
+		Expect(s.Trigger("slow")).To(MatchError(ErrJobRunning))
+
+		close(release)
+		Eventually(func() error { return s.Trigger("slow") }).Should(Succeed())
+	})
+
+	It("should report the status of failing jobs", func() {
+		Expect(s.Register(Job{Name: "failing", Schedule: "@daily", Run: func(context.Context) error {
+			return errors.New("boom")
+		}})).To(Succeed())
+
+		Expect(s.Trigger("failing")).To(Succeed())
+		Eventually(func() int {
+			status, _ := s.GetJob("failing")
+			return status.ConsecutiveFailures
+		}).Should(Equal(1))
+
+		Eventually(func() error { return s.Trigger("failing") }).Should(Succeed())
+		Eventually(func() int {
+			status, _ := s.GetJob("failing")
+			return status.ConsecutiveFailures
+		}).Should(Equal(2))
+
+		statuses := s.ListJobs()
+		Expect(statuses).To(HaveLen(1))
+		Expect(statuses[0].LastError).To(Equal("boom"))
+		Expect(statuses[0].LastSucceededAt).To(BeNil())
+		Expect(statuses[0].Stalled).To(BeFalse())
+	})
+
+	It("should skip runs while another replica holds the lease", func() {
+		locked := New(logger.New(environments.Test), leaserFunc(func(context.Context, string, time.Time) (Lease, bool, error) {
+			return nil, false, nil
+		}))
+		defer locked.Stop()
+
+		runs := 0
+		Expect(locked.Register(Job{Name: "single", Schedule: "@daily", Run: func(context.Context) error {
+			runs++
+			return nil
+		}})).To(Succeed())
+
+		Expect(locked.Trigger("single")).To(Succeed())
+		Eventually(func() int {
+			status, _ := locked.GetJob("single")
+			return status.LockSkips
+		}).Should(Equal(1))
+		Expect(runs).To(Equal(0))
+	})
+
+	It("should run a scheduled tick once across replicas", func() {
+		leaser := &tickLeaser{ticks: map[string]time.Time{}}
+		runs := 0
+		job := Job{Name: "refresh", Schedule: "* * * * *", Run: func(context.Context) error {
+			runs++
+			return nil
+		}}
+
+		replicas := []*Scheduler{}
+		for range 2 {
+			replica := New(logger.New(environments.Test), leaser).(*Scheduler)
+			defer replica.Stop()
+			Expect(replica.Register(job)).To(Succeed())
+			replicas = append(replicas, replica)
+		}
+
+		tick := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		// the second replica starts after the first one finished, e.g because of jitter
+		replicas[0].execute(replicas[0].jobs["refresh"], "schedule", tick)
+		replicas[1].execute(replicas[1].jobs["refresh"], "schedule", tick)
+		Expect(runs).To(Equal(1))
+
+		replicas[1].execute(replicas[1].jobs["refresh"], "schedule", tick.Add(time.Minute))
+		Expect(runs).To(Equal(2))
+		Expect(leaser.manual).To(Equal(0))
+
+		Expect(replicas[1].GetJob("refresh")).To(HaveField("LockSkips", 1))
+	})
+
+	It("should renew the lease while running and cancel the run once it is lost", func() {
+		lease := &fakeLease{}
+		leased := New(logger.New(environments.Test), leaserFunc(func(context.Context, string, time.Time) (Lease, bool, error) {
+			return lease, true, nil
+		})).(*Scheduler)
+		leased.leaseTTL = 30 * time.Millisecond
+		defer leased.Stop()
+
+		cancelled := make(chan error, 1)
+		Expect(leased.Register(Job{Name: "slow", Schedule: "@daily", Run: func(ctx context.Context) error {
+			for lease.renewals.Load() < 2 {
+				time.Sleep(5 * time.Millisecond)
+			}
+			lease.lost.Store(true)
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return ctx.Err()
+		}})).To(Succeed())
+
+		Expect(leased.Trigger("slow")).To(Succeed())
+		Eventually(cancelled).Should(Receive(MatchError(context.Canceled)))
+		Eventually(lease.released.Load).Should(BeTrue())
+		Eventually(func() string {
+			status, _ := leased.GetJob("slow")
+			return status.LastError
+		}).Should(Equal(context.Canceled.Error()))
+	})
+
+	It("should run local jobs without a lease", func() {
+		leases := 0
+		leased := New(logger.New(environments.Test), leaserFunc(func(context.Context, string, time.Time) (Lease, bool, error) {
+			leases++
+			return nil, false, nil
+		}))
+		defer leased.Stop()
+
+		var runs atomic.Int32
+		Expect(leased.Register(Job{Name: "cache", Schedule: "@daily", Local: true, Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		}})).To(Succeed())
+
+		Expect(leased.Trigger("cache")).To(Succeed())
+		Eventually(runs.Load).Should(Equal(int32(1)))
+		Expect(leases).To(Equal(0))
+	})
+
+	It("should fail to trigger an unknown job", func() {
+		Expect(s.Trigger("unknown")).To(MatchError(ErrJobNotFound))
+	})
+})
+
+type leaserFunc func(ctx context.Context, name string, tick time.Time) (Lease, bool, error)
+
+func (f leaserFunc) AcquireLease(ctx context.Context, name string, tick time.Time, ttl time.Duration) (Lease, bool, error) {
+	return f(ctx, name, tick)
+}
+
+// tickLeaser claims ticks like the postgres job_leases table, runs never overlap in tests
+type tickLeaser struct {
+	ticks  map[string]time.Time
+	manual int
+}
+
+func (l *tickLeaser) AcquireLease(ctx context.Context, name string, tick time.Time, ttl time.Duration) (Lease, bool, error) {
+	if tick.IsZero() {
+		l.manual++
+		return &fakeLease{}, true, nil
+	}
+	if !l.ticks[name].Before(tick) {
+		return nil, false, nil
+	}
+	l.ticks[name] = tick
+	return &fakeLease{}, true, nil
+}
+
+// fakeLease fails renewals once lost is set
+type fakeLease struct {
+	lost     atomic.Bool
+	renewals atomic.Int32
+	released atomic.Bool
+}
+
+func (l *fakeLease) Renew(context.Context) error {
+	if l.lost.Load() {
+		return errors.New("lease lost")
+	}
+	l.renewals.Add(1)
+	return nil
+}
+
+func (l *fakeLease) Release() {
+	l.released.Store(true)
+}
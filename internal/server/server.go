@@ -1,23 +1,249 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/attestation"
+	"github.com/dwarvesf/icy-backend/internal/auth"
 	"github.com/dwarvesf/icy-backend/internal/btcrpc"
+	"github.com/dwarvesf/icy-backend/internal/chaos"
+	"github.com/dwarvesf/icy-backend/internal/health"
 	"github.com/dwarvesf/icy-backend/internal/oracle"
+	"github.com/dwarvesf/icy-backend/internal/scheduler"
+	"github.com/dwarvesf/icy-backend/internal/store"
 	pgstore "github.com/dwarvesf/icy-backend/internal/store/postgres"
+	"github.com/dwarvesf/icy-backend/internal/stream"
 	"github.com/dwarvesf/icy-backend/internal/transport/http"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
 )
 
 func Init() {
-	appConfig := config.New()
-	logger := logger.New(appConfig.Environment)
+	appConfig, err := config.New()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	logger := logger.NewWithOptions(appConfig.Environment, logger.Options{
+		Level:         appConfig.Log.Level,
+		Format:        appConfig.Log.Format,
+		PackageLevels: appConfig.Log.PackageLevels,
+		Sampling:      appConfig.Log.Sampling,
+	})
+	configWatcher := config.NewWatcher(appConfig)
+
+	pg := pgstore.New(appConfig, logger)
+	svc := newServices(appConfig, logger, configWatcher, pg)
+	svc.scheduler.Start()
+	go svc.streamHub.Run()
+
+	httpServer := &nethttp.Server{
+		Addr:    ":" + appConfig.ApiServer.Port,
+		Handler: svc.httpHandler(appConfig, configWatcher, logger),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go watchConfig(ctx, appConfig, logger, configWatcher)
+
+	go func() {
+		logger.Info("http server is listening", map[string]string{"addr": httpServer.Addr})
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			logger.Fatal("http server stopped unexpectedly", map[string]string{"error": err.Error()})
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down, draining in-flight requests", map[string]string{
+		"timeout": appConfig.ApiServer.ShutdownTimeout.String(),
+	})
+
+	shutdown(appConfig, logger, httpServer, svc.streamHub, svc.scheduler, pg)
+}
+
+// database is the part of the postgres store the services depend on
+type database interface {
+	scheduler.Leaser
+	DB() *gorm.DB
+	ReadDB() *gorm.DB
+	Ping(ctx context.Context) error
+	PingReplica(ctx context.Context) error
+}
+
+// services are the dependencies of the http server. Optional ones are nil
+// when the deployment profile disables them
+type services struct {
+	auth        auth.IAuth
+	oracle      oracle.IOracle
+	streamHub   stream.IHub
+	health      health.IHealth
+	attestation attestation.IAttestation
+	chaos       chaos.IChaos
+	// scheduler runs the jobs, adminScheduler is nil when they can't be managed
+	scheduler      scheduler.IScheduler
+	adminScheduler scheduler.IScheduler
+}
+
+// newServices builds the services of the deployment profile, the scheduler and
+// the stream hub are not started
+func newServices(appConfig *config.AppConfig, logger *logger.Logger, configWatcher *config.Watcher, pg database) *services {
+	store := store.New()
+
+	readOnly := appConfig.DeploymentProfile == profiles.PublicReadOnly
+	if readOnly {
+		logger.Info("running in public read-only mode, signing and payouts are disabled")
+	}
+
+	// the public mirror ignores API keys, so requests never look them up
+	var authSvc auth.IAuth
+	if !readOnly {
+		authSvc = auth.New(appConfig, logger, pg.DB(), store)
+	}
+
+	btcRpc := btcrpc.New(appConfig, logger.Named("btcrpc"))
+	switch {
+	case readOnly:
+		btcRpc = btcrpc.NewReadOnly(btcRpc)
+	case appConfig.DryRun:
+		logger.Info("running in dry-run mode, BTC sends are simulated")
+		btcRpc = btcrpc.NewDryRun(btcRpc, appConfig, logger.Named("btcrpc"), pg.DB(), store)
+	}
+
+	// chaos injection is only allowed outside production
+	var chaosInjector chaos.IChaos
+	if appConfig.Chaos.Enabled && appConfig.Environment != environments.Production && !readOnly {
+		logger.Info("chaos injection is enabled")
+		chaosInjector = chaos.New(logger.Named("chaos"))
+		btcRpc = chaos.WrapBtcRpc(btcRpc, chaosInjector)
+	}
+
+	oracle := oracle.New(appConfig, logger.Named("oracle"), btcRpc, pg.DB(), pg.ReadDB(), store)
+	if chaosInjector != nil {
+		oracle = chaos.WrapOracle(oracle, chaosInjector)
+	}
+
+	configWatcher.Subscribe(func(_, new *config.AppConfig) {
+		oracle.SetCacheTTL(new.Oracle.CacheTTL)
+	})
+
+	// every replica refreshes its own oracle cache, the rates are recorded by a
+	// single replica and never by the public mirror
+	jobs := []scheduler.Job{{
+		Name:     "oracle-refresh",
+		Schedule: appConfig.Jobs.OracleRefresh.Schedule,
+		Jitter:   appConfig.Jobs.OracleRefresh.Jitter,
+		Local:    true,
+		Run:      oracle.RefreshCache,
+	}}
+	if !readOnly {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "rate-record",
+			Schedule: appConfig.Jobs.RateRecord.Schedule,
+			Jitter:   appConfig.Jobs.RateRecord.Jitter,
+			Run:      oracle.RecordICYBTCRate,
+		})
+	}
+	jobScheduler := scheduler.New(logger.Named("scheduler"), pg)
+	for _, job := range jobs {
+		if err := jobScheduler.Register(job); err != nil {
+			logger.Fatal("failed to register job", map[string]string{"error": err.Error()})
+		}
+	}
+
+	// jobs can't be triggered from the public mirror
+	var adminScheduler scheduler.IScheduler
+	if !readOnly {
+		adminScheduler = jobScheduler
+	}
+
+	// attestation loads the server signing key, which must not be present in read-only mode
+	var attestationSvc attestation.IAttestation
+	if !readOnly {
+		attestationSvc = attestation.New(appConfig, logger.Named("attestation"), btcRpc)
+	}
+
+	streamHub := stream.New(appConfig, logger.Named("stream"), oracle)
+
+	probes := []health.Probe{{Name: "postgres", Check: pg.Ping}}
+	if appConfig.Postgres.ReplicaHost != "" {
+		probes = append(probes, health.Probe{Name: "postgres-replica", Check: pg.PingReplica})
+	}
+	healthSvc := health.New(appConfig, logger.Named("health"), probes...)
+
+	return &services{
+		auth:           authSvc,
+		oracle:         oracle,
+		streamHub:      streamHub,
+		health:         healthSvc,
+		attestation:    attestationSvc,
+		chaos:          chaosInjector,
+		scheduler:      jobScheduler,
+		adminScheduler: adminScheduler,
+	}
+}
+
+// httpHandler routes the requests to the services
+func (s *services) httpHandler(appConfig *config.AppConfig, configWatcher *config.Watcher, logger *logger.Logger) nethttp.Handler {
+	return http.NewHttpServer(appConfig, configWatcher, logger, s.auth, s.oracle, s.streamHub, s.health, s.attestation, s.chaos, s.adminScheduler)
+}
+
+// watchConfig reloads the config on SIGHUP and, when enabled, on every poll interval
+func watchConfig(ctx context.Context, appConfig *config.AppConfig, logger *logger.Logger, configWatcher *config.Watcher) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var poll <-chan time.Time
+	if appConfig.ConfigReload.PollInterval > 0 {
+		ticker := time.NewTicker(appConfig.ConfigReload.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-poll:
+		}
+
+		if err := configWatcher.Reload(); err != nil {
+			logger.Error("failed to reload config, keeping the current one", map[string]string{"error": err.Error()})
+		}
+	}
+}
+
+// shutdown stops accepting requests, waits for in-flight ones up to the drain
+// timeout, then stops background work and closes the clients
+func shutdown(appConfig *config.AppConfig, logger *logger.Logger, httpServer *nethttp.Server, streamHub stream.IHub, jobScheduler scheduler.IScheduler, pg *pgstore.PostgresStore) {
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.ApiServer.ShutdownTimeout)
+	defer cancel()
+
+	// long-lived SSE streams would otherwise hold the drain until the timeout
+	streamHub.Stop()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logger.Error("http server did not drain in time", map[string]string{"error": err.Error()})
+	}
 
-	_ = pgstore.New(appConfig, logger)
-	btcRpc := btcrpc.New(appConfig, logger)
-	oracle := oracle.New(appConfig, logger, btcRpc)
+	jobScheduler.Stop()
 
-	httpServer := http.NewHttpServer(appConfig, logger, oracle)
+	if err := pg.Close(); err != nil {
+		logger.Error("failed to close postgres", map[string]string{"error": err.Error()})
+	}
 
-	httpServer.Run()
+	logger.Info("shutdown completed")
 }
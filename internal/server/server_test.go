@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/scheduler"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+// recordingDatabase never connects, it records the statements and leases
+// the services ask for
+type recordingDatabase struct {
+	mux        sync.Mutex
+	db         *gorm.DB
+	statements []string
+	leases     []string
+}
+
+func newRecordingDatabase() *recordingDatabase {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	Expect(err).NotTo(HaveOccurred())
+
+	d := &recordingDatabase{db: db}
+	record := func(tx *gorm.DB) {
+		d.mux.Lock()
+		defer d.mux.Unlock()
+		d.statements = append(d.statements, tx.Statement.SQL.String())
+	}
+	Expect(db.Callback().Query().After("gorm:query").Register("test:record", record)).To(Succeed())
+	Expect(db.Callback().Create().After("gorm:create").Register("test:record", record)).To(Succeed())
+	Expect(db.Callback().Raw().After("gorm:raw").Register("test:record", record)).To(Succeed())
+
+	return d
+}
+
+func (d *recordingDatabase) DB() *gorm.DB                      { return d.db }
+func (d *recordingDatabase) ReadDB() *gorm.DB                  { return d.db }
+func (d *recordingDatabase) Ping(context.Context) error        { return nil }
+func (d *recordingDatabase) PingReplica(context.Context) error { return nil }
+
+func (d *recordingDatabase) AcquireLease(ctx context.Context, name string, tick time.Time, ttl time.Duration) (scheduler.Lease, bool, error) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.leases = append(d.leases, name)
+	return nil, false, nil
+}
+
+func (d *recordingDatabase) recorded() (statements, leases []string) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return append([]string{}, d.statements...), append([]string{}, d.leases...)
+}
+
+var _ = Describe("Services", func() {
+	var (
+		appConfig *config.AppConfig
+		log       *logger.Logger
+		db        *recordingDatabase
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		appConfig = &config.AppConfig{
+			Environment:       environments.Test,
+			DeploymentProfile: profiles.PublicReadOnly,
+			ApiServer:         config.ApiServerConfig{AllowedOrigins: "*"},
+			Chaos:             config.ChaosConfig{Enabled: true},
+			Jobs: config.JobsConfig{
+				OracleRefresh: config.JobConfig{Schedule: "* * * * *"},
+				RateRecord:    config.JobConfig{Schedule: "* * * * *"},
+			},
+		}
+		log = logger.New(environments.Test)
+		db = newRecordingDatabase()
+	})
+
+	newServer := func() (*services, http.Handler) {
+		svc := newServices(appConfig, log, config.NewWatcher(appConfig), db)
+		DeferCleanup(svc.scheduler.Stop)
+		return svc, svc.httpHandler(appConfig, config.NewWatcher(appConfig), log)
+	}
+
+	Describe("public read-only profile", func() {
+		It("should build no signing, fault injection, API key or job management", func() {
+			svc, _ := newServer()
+
+			Expect(svc.auth).To(BeNil())
+			Expect(svc.attestation).To(BeNil())
+			Expect(svc.chaos).To(BeNil())
+			Expect(svc.adminScheduler).To(BeNil())
+		})
+
+		It("should only run the oracle cache refresh, without a lease", func() {
+			svc, _ := newServer()
+
+			jobs := svc.scheduler.ListJobs()
+			Expect(jobs).To(HaveLen(1))
+			Expect(jobs[0].Name).To(Equal("oracle-refresh"))
+
+			Expect(svc.scheduler.Trigger("oracle-refresh")).To(Succeed())
+			Eventually(func() *time.Time {
+				job, _ := svc.scheduler.GetJob("oracle-refresh")
+				return job.LastFinishedAt
+			}).ShouldNot(BeNil())
+			_, leases := db.recorded()
+			Expect(leases).To(BeEmpty())
+		})
+
+		It("should serve reads without querying API keys and reject the rest", func() {
+			_, r := newServer()
+
+			for _, tc := range []struct {
+				method, path string
+				code         int
+			}{
+				{http.MethodGet, "/api/v1/fees", http.StatusOK},
+				{http.MethodGet, "/api/v1/admin/jobs", http.StatusNotFound},
+				{http.MethodGet, "/api/v1/attestation/reserves", http.StatusNotFound},
+				{http.MethodPost, "/api/v1/admin/jobs/oracle-refresh/run", http.StatusMethodNotAllowed},
+			} {
+				req := httptest.NewRequest(tc.method, tc.path, nil)
+				req.Header.Set("X-API-Key", "icy_made_up")
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(tc.code), tc.method+" "+tc.path)
+			}
+			statements, _ := db.recorded()
+			Expect(statements).To(BeEmpty())
+		})
+	})
+
+	Describe("full profile", func() {
+		BeforeEach(func() {
+			appConfig.DeploymentProfile = profiles.Full
+		})
+
+		It("should build every service and lease the rate recording", func() {
+			svc, r := newServer()
+
+			Expect(svc.auth).NotTo(BeNil())
+			Expect(svc.chaos).NotTo(BeNil())
+			Expect(svc.adminScheduler).NotTo(BeNil())
+
+			jobs := svc.scheduler.ListJobs()
+			Expect(jobs).To(HaveLen(2))
+			Expect(jobs[1].Name).To(Equal("rate-record"))
+
+			Expect(svc.scheduler.Trigger("rate-record")).To(Succeed())
+			Eventually(func() []string {
+				_, leases := db.recorded()
+				return leases
+			}).Should(Equal([]string{"rate-record"}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/fees", nil)
+			req.Header.Set("X-API-Key", "icy_made_up")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			statements, _ := db.recorded()
+			Expect(statements).To(ContainElement(ContainSubstring("api_keys")))
+		})
+	})
+})
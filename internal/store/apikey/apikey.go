@@ -0,0 +1,28 @@
+package apikey
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+type store struct{}
+
+func New() IStore {
+	return &store{}
+}
+
+func (s *store) Create(ctx context.Context, db *gorm.DB, apiKey *model.ApiKey) error {
+	return db.WithContext(ctx).Create(apiKey).Error
+}
+
+func (s *store) GetByKeyHash(ctx context.Context, db *gorm.DB, keyHash string) (*model.ApiKey, error) {
+	var apiKey model.ApiKey
+	if err := db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
@@ -0,0 +1,14 @@
+package apikey
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+type IStore interface {
+	Create(ctx context.Context, db *gorm.DB, apiKey *model.ApiKey) error
+	GetByKeyHash(ctx context.Context, db *gorm.DB, keyHash string) (*model.ApiKey, error)
+}
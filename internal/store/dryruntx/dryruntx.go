@@ -0,0 +1,19 @@
+package dryruntx
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+type store struct{}
+
+func New() IStore {
+	return &store{}
+}
+
+func (s *store) Create(ctx context.Context, db *gorm.DB, tx *model.DryRunTransaction) error {
+	return db.WithContext(ctx).Create(tx).Error
+}
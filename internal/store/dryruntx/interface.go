@@ -0,0 +1,13 @@
+package dryruntx
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+type IStore interface {
+	Create(ctx context.Context, db *gorm.DB, tx *model.DryRunTransaction) error
+}
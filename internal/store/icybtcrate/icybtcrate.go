@@ -0,0 +1,43 @@
+package icybtcrate
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+type store struct{}
+
+func New() IStore {
+	return &store{}
+}
+
+func (s *store) Create(ctx context.Context, db *gorm.DB, rate *model.IcyBtcRate) error {
+	return db.WithContext(ctx).Create(rate).Error
+}
+
+func (s *store) ListCandles(ctx context.Context, db *gorm.DB, from, to time.Time, interval model.RateInterval) ([]model.IcyBtcRateCandle, error) {
+	candles := []model.IcyBtcRateCandle{}
+	err := db.WithContext(ctx).Raw(`
+		SELECT
+			date_trunc(?, created_at) AS time,
+			(array_agg(value ORDER BY created_at ASC))[1] AS open,
+			MAX(value) AS high,
+			MIN(value) AS low,
+			(array_agg(value ORDER BY created_at DESC))[1] AS close,
+			MAX("decimal") AS decimal
+		FROM icy_btc_rates
+		WHERE created_at >= ? AND created_at < ?
+		GROUP BY 1
+		ORDER BY 1`,
+		string(interval), from, to,
+	).Scan(&candles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return candles, nil
+}
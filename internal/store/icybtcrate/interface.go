@@ -0,0 +1,17 @@
+package icybtcrate
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+)
+
+type IStore interface {
+	Create(ctx context.Context, db *gorm.DB, rate *model.IcyBtcRate) error
+
+	// ListCandles returns the OHLC per interval of the rates recorded in [from, to)
+	ListCandles(ctx context.Context, db *gorm.DB, from, to time.Time, interval model.RateInterval) ([]model.IcyBtcRateCandle, error)
+}
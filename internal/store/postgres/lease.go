@@ -0,0 +1,84 @@
+package pgstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/scheduler"
+)
+
+var errLeaseLost = errors.New("job lease expired or was taken over")
+
+// AcquireLease takes the lease of the job name for ttl if no replica holds it.
+// A non zero tick is claimed too, the lease is refused when that tick or a
+// later one was already claimed, so every scheduled run happens once. The
+// lease is a row rather than a session lock, it outlives dropped connections
+// and expires when a crashed replica stops renewing it
+func (s *PostgresStore) AcquireLease(ctx context.Context, name string, tick time.Time, ttl time.Duration) (scheduler.Lease, bool, error) {
+	query := `
+		INSERT INTO job_leases (name, last_tick, locked_until)
+		VALUES (@name, @tick, now() + @ttl * interval '1 microsecond')
+		ON CONFLICT (name) DO UPDATE
+		SET last_tick = GREATEST(job_leases.last_tick, EXCLUDED.last_tick), locked_until = EXCLUDED.locked_until
+		WHERE job_leases.locked_until <= now()`
+	if !tick.IsZero() {
+		query += ` AND job_leases.last_tick < EXCLUDED.last_tick`
+	}
+	query += ` RETURNING locked_until`
+
+	var lockedUntil []time.Time
+	err := s.db.WithContext(ctx).Raw(query, map[string]any{
+		"name": name,
+		"tick": tick,
+		"ttl":  ttl.Microseconds(),
+	}).Scan(&lockedUntil).Error
+	if err != nil {
+		return nil, false, err
+	}
+	if len(lockedUntil) == 0 {
+		return nil, false, nil
+	}
+
+	return &jobLease{db: s.db, name: name, ttl: ttl, lockedUntil: lockedUntil[0]}, true, nil
+}
+
+// jobLease is identified by its locked_until, which changes on every renewal,
+// so a replica can't renew or release a lease taken over after expiring
+type jobLease struct {
+	db          *gorm.DB
+	name        string
+	ttl         time.Duration
+	lockedUntil time.Time
+}
+
+func (l *jobLease) Renew(ctx context.Context) error {
+	var lockedUntil []time.Time
+	err := l.db.WithContext(ctx).Raw(`
+		UPDATE job_leases SET locked_until = now() + @ttl * interval '1 microsecond'
+		WHERE name = @name AND locked_until = @token AND locked_until > now()
+		RETURNING locked_until`, map[string]any{
+		"name":  l.name,
+		"token": l.lockedUntil,
+		"ttl":   l.ttl.Microseconds(),
+	}).Scan(&lockedUntil).Error
+	if err != nil {
+		return err
+	}
+	if len(lockedUntil) == 0 {
+		return errLeaseLost
+	}
+	l.lockedUntil = lockedUntil[0]
+
+	return nil
+}
+
+func (l *jobLease) Release() {
+	// release on a fresh context, the one of the run may be cancelled by now
+	l.db.WithContext(context.Background()).Exec(
+		"UPDATE job_leases SET locked_until = now() WHERE name = ? AND locked_until = ?",
+		l.name, l.lockedUntil,
+	)
+}
@@ -0,0 +1,125 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+)
+
+// testStore connects to the test database of the devbox, the specs are
+// skipped when it is not running
+func testStore() *PostgresStore {
+	getenv := func(key, fallback string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return fallback
+	}
+	appConfig := &config.AppConfig{Postgres: config.DBConnection{
+		User:            getenv("DB_USER", "postgres"),
+		Pass:            getenv("DB_PASS", "postgres"),
+		Name:            getenv("DB_NAME", "icy_backend_local") + "_test",
+		SSLMode:         "disable",
+		QueryTimeout:    5 * time.Second,
+		MaxOpenConns:    5,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: time.Minute,
+	}}
+
+	db, err := connectPostgres(appConfig, getenv("DB_HOST", "localhost"), getenv("DB_PORT_TEST", "35432"))
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		store := &PostgresStore{db: db, replica: db}
+		if err = store.Ping(ctx); err == nil {
+			Expect(db.AutoMigrate(&model.JobLease{})).To(Succeed())
+			return store
+		}
+	}
+	Skip(fmt.Sprintf("test database is not available: %v", err))
+
+	return nil
+}
+
+var _ = Describe("Job leases", func() {
+	var (
+		store *PostgresStore
+		ctx   context.Context
+		name  string
+	)
+
+	BeforeEach(func() {
+		store = testStore()
+		ctx = context.Background()
+		name = fmt.Sprintf("lease-test-%d", time.Now().UnixNano())
+		DeferCleanup(func() {
+			store.db.Exec("DELETE FROM job_leases WHERE name = ?", name)
+			store.Close()
+		})
+	})
+
+	It("should let a single replica hold the lease until it is released", func() {
+		lease, acquired, err := store.AcquireLease(ctx, name, time.Time{}, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+
+		_, acquired, err = store.AcquireLease(ctx, name, time.Time{}, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeFalse())
+
+		Expect(lease.Renew(ctx)).To(Succeed())
+		lease.Release()
+
+		_, acquired, err = store.AcquireLease(ctx, name, time.Time{}, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+	})
+
+	It("should claim every scheduled tick once", func() {
+		tick := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		lease, acquired, err := store.AcquireLease(ctx, name, tick, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+		lease.Release()
+
+		_, acquired, err = store.AcquireLease(ctx, name, tick, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeFalse())
+
+		_, acquired, err = store.AcquireLease(ctx, name, tick.Add(-time.Minute), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeFalse())
+
+		_, acquired, err = store.AcquireLease(ctx, name, tick.Add(time.Minute), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+	})
+
+	It("should let another replica take an expired lease over", func() {
+		expired, acquired, err := store.AcquireLease(ctx, name, time.Time{}, 10*time.Millisecond)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+		time.Sleep(50 * time.Millisecond)
+
+		Expect(expired.Renew(ctx)).To(MatchError(errLeaseLost))
+
+		lease, acquired, err := store.AcquireLease(ctx, name, time.Time{}, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+
+		// the expired lease can neither renew nor release the new one
+		expired.Release()
+		Expect(expired.Renew(ctx)).To(MatchError(errLeaseLost))
+		_, acquired, err = store.AcquireLease(ctx, name, time.Time{}, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeFalse())
+		Expect(lease.Renew(ctx)).To(Succeed())
+	})
+})
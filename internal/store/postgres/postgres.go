@@ -1,42 +1,119 @@
 package pgstore
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
 
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
 )
 
 type PostgresStore struct {
-	// db *gorm.DB
+	db      *gorm.DB
+	replica *gorm.DB
 }
 
 func New(appConfig *config.AppConfig, logger *logger.Logger) *PostgresStore {
-	_, err := connectPostgres(appConfig)
+	conn, err := connectPostgres(appConfig, appConfig.Postgres.Host, appConfig.Postgres.Port)
 	if err != nil {
 		logger.Fatal("failed to connect to postgres", map[string]string{
 			"error": err.Error(),
 		})
 	}
 
-	return &PostgresStore{
-		// db: conn,
+	// the public read-only mirror never changes the schema, the full deployment migrates it
+	if appConfig.DeploymentProfile != profiles.PublicReadOnly {
+		if err := conn.AutoMigrate(
+			&model.ApiKey{},
+			&model.IcyBtcRate{},
+			&model.JobLease{},
+			&model.DryRunTransaction{},
+		); err != nil {
+			logger.Fatal("failed to migrate postgres", map[string]string{
+				"error": err.Error(),
+			})
+		}
 	}
+
+	s := &PostgresStore{
+		db:      conn,
+		replica: conn,
+	}
+
+	if appConfig.Postgres.ReplicaHost != "" {
+		s.replica, err = connectPostgres(appConfig, appConfig.Postgres.ReplicaHost, appConfig.Postgres.ReplicaPort)
+		if err != nil {
+			logger.Fatal("failed to connect to postgres replica", map[string]string{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return s
+}
+
+// DB returns the primary, used for writes and reads which must see them
+func (s *PostgresStore) DB() *gorm.DB {
+	return s.db
+}
+
+// ReadDB returns the read replica, or the primary when no replica is configured.
+// Reads from it may lag behind the primary
+func (s *PostgresStore) ReadDB() *gorm.DB {
+	return s.replica
+}
+
+// Ping checks the connection to postgres, used by the readiness probe
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+// PingReplica checks the connection to the read replica
+func (s *PostgresStore) PingReplica(ctx context.Context) error {
+	sqlDB, err := s.replica.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
 }
 
-func connectPostgres(appConfig *config.AppConfig) (*gorm.DB, error) {
+func (s *PostgresStore) Close() error {
+	if s.replica != s.db {
+		if sqlDB, err := s.replica.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}
+
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+func connectPostgres(appConfig *config.AppConfig, host, port string) (*gorm.DB, error) {
 	ds := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		appConfig.Postgres.Host,
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s statement_timeout=%d",
+		host,
 		appConfig.Postgres.User,
 		appConfig.Postgres.Pass,
 		appConfig.Postgres.Name,
-		appConfig.Postgres.Port,
+		port,
 		appConfig.Postgres.SSLMode,
+		appConfig.Postgres.QueryTimeout.Milliseconds(),
 	)
 
 	db, err := gorm.Open(postgres.Open(ds),
@@ -49,5 +126,13 @@ func connectPostgres(appConfig *config.AppConfig) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(appConfig.Postgres.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(appConfig.Postgres.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(appConfig.Postgres.ConnMaxLifetime)
+
 	return db, nil
 }
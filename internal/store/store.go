@@ -0,0 +1,21 @@
+package store
+
+import (
+	"github.com/dwarvesf/icy-backend/internal/store/apikey"
+	"github.com/dwarvesf/icy-backend/internal/store/dryruntx"
+	"github.com/dwarvesf/icy-backend/internal/store/icybtcrate"
+)
+
+type Store struct {
+	ApiKey     apikey.IStore
+	IcyBtcRate icybtcrate.IStore
+	DryRunTx   dryruntx.IStore
+}
+
+func New() *Store {
+	return &Store{
+		ApiKey:     apikey.New(),
+		IcyBtcRate: icybtcrate.New(),
+		DryRunTx:   dryruntx.New(),
+	}
+}
@@ -0,0 +1,125 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/oracle"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+// subscriberBufferSize bounds the events queued for a slow client, events are
+// dropped for that client once its buffer is full
+const subscriberBufferSize = 16
+
+type Hub struct {
+	mux         *sync.RWMutex
+	subscribers map[chan Event]struct{}
+	latest      map[string]Event
+	stop        chan struct{}
+	// stopped is guarded by mux, subscribers arriving after Stop get a closed channel
+	stopped bool
+
+	appConfig *config.AppConfig
+	logger    *logger.Logger
+	oracle    oracle.IOracle
+}
+
+func New(appConfig *config.AppConfig, logger *logger.Logger, oracle oracle.IOracle) IHub {
+	return &Hub{
+		mux:         &sync.RWMutex{},
+		subscribers: map[chan Event]struct{}{},
+		latest:      map[string]Event{},
+		stop:        make(chan struct{}),
+		appConfig:   appConfig,
+		logger:      logger,
+		oracle:      oracle,
+	}
+}
+
+func (h *Hub) Run() {
+	ticker := time.NewTicker(h.appConfig.Stream.PollInterval)
+	defer ticker.Stop()
+
+	h.pollICYBTCRatio()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			h.pollICYBTCRatio()
+		}
+	}
+}
+
+func (h *Hub) Stop() {
+	close(h.stop)
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.stopped = true
+	for ch := range h.subscribers {
+		close(ch)
+		delete(h.subscribers, ch)
+	}
+}
+
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBufferSize)
+
+	h.mux.Lock()
+	if h.stopped {
+		h.mux.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	for _, event := range h.latest {
+		ch <- event
+	}
+	h.subscribers[ch] = struct{}{}
+	h.mux.Unlock()
+
+	unsubscribe := func() {
+		h.mux.Lock()
+		defer h.mux.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+func (h *Hub) pollICYBTCRatio() {
+	ratio, err := h.oracle.GetCachedRealtimeICYBTC()
+	if err != nil {
+		h.logger.Error("[stream] can't get cached ICY/BTC ratio", map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.mux.RLock()
+	previous, ok := h.latest[EventICYBTCRatio]
+	h.mux.RUnlock()
+	if ok && *previous.Data.(*model.Web3BigInt) == *ratio {
+		return
+	}
+
+	h.broadcast(Event{Type: EventICYBTCRatio, Data: ratio})
+}
+
+func (h *Hub) broadcast(event Event) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.latest[event.Type] = event
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// slow subscriber, drop the event rather than blocking everyone
+		}
+	}
+}
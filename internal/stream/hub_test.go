@@ -0,0 +1,39 @@
+package stream
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+var _ = Describe("Hub", func() {
+	var hub IHub
+
+	BeforeEach(func() {
+		appConfig := &config.AppConfig{Stream: config.StreamConfig{PollInterval: time.Second}}
+		hub = New(appConfig, logger.New(environments.Test), nil)
+	})
+
+	It("should close the subscriber channels on stop", func() {
+		events, unsubscribe := hub.Subscribe()
+		defer unsubscribe()
+
+		hub.Stop()
+
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("should return a closed channel to subscribers arriving after stop", func() {
+		hub.Stop()
+
+		events, unsubscribe := hub.Subscribe()
+		defer unsubscribe()
+
+		Expect(events).To(BeClosed())
+	})
+})
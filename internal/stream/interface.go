@@ -0,0 +1,23 @@
+package stream
+
+const (
+	EventICYBTCRatio = "icy_btc_ratio"
+)
+
+type Event struct {
+	Type string
+	Data any
+}
+
+type IHub interface {
+	// Run polls the sources and broadcasts changes until Stop is called
+	Run()
+
+	// Stop stops polling and closes every subscriber channel
+	Stop()
+
+	// Subscribe returns a channel receiving every broadcasted event, starting
+	// with the latest known ones, and a function to unsubscribe. The channel is
+	// already closed once the hub is stopped
+	Subscribe() (<-chan Event, func())
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dwarvesf/icy-backend/internal/auth"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+	"github.com/dwarvesf/icy-backend/internal/view"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+
+	// ContextKeyApiKey holds the authenticated *model.ApiKey
+	ContextKeyApiKey = "api_key"
+)
+
+// authenticate resolves the API key of the request, if any. It aborts the request
+// and returns false when a key is given but is invalid. Keys are ignored when a
+// is nil, as in the public read-only profile
+func authenticate(c *gin.Context, a auth.IAuth, logger *logger.Logger) (*model.ApiKey, bool) {
+	if apiKey, ok := c.Get(ContextKeyApiKey); ok {
+		return apiKey.(*model.ApiKey), true
+	}
+
+	rawKey := c.GetHeader(apiKeyHeader)
+	if rawKey == "" || a == nil {
+		return nil, true
+	}
+
+	apiKey, err := a.Authenticate(c.Request.Context(), rawKey)
+	if err != nil {
+		if !errors.Is(err, auth.ErrInvalidApiKey) {
+			logger.Error("[auth] failed to authenticate API key", map[string]string{"error": err.Error()})
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized,
+			view.CreateErrorResponse(nil, nil, view.ErrCodeUnauthorized, c.GetHeader("Accept-Language")))
+		return nil, false
+	}
+
+	c.Set(ContextKeyApiKey, apiKey)
+	c.Set(ContextKeyRateLimitKey, strconv.FormatUint(uint64(apiKey.ID), 10))
+
+	return apiKey, true
+}
+
+// optionalApiKey authenticates the API key when present, so requests are rate
+// limited per key, and lets anonymous requests through
+func optionalApiKey(a auth.IAuth, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := authenticate(c, a, logger); !ok {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireRole only lets through requests with an API key granting role
+func requireRole(a auth.IAuth, logger *logger.Logger, role model.ApiKeyRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey, ok := authenticate(c, a, logger)
+		if !ok {
+			return
+		}
+
+		if apiKey == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized,
+				view.CreateErrorResponse(nil, nil, view.ErrCodeUnauthorized, c.GetHeader("Accept-Language")))
+			return
+		}
+
+		if !apiKey.Role.Includes(role) {
+			logger.Info("[auth] API key lacks the required role", map[string]string{
+				"api_key": apiKey.Name,
+				"role":    string(apiKey.Role),
+				"path":    c.FullPath(),
+			})
+			c.AbortWithStatusJSON(http.StatusForbidden,
+				view.CreateErrorResponse(nil, nil, view.ErrCodeForbidden, c.GetHeader("Accept-Language")))
+			return
+		}
+
+		c.Next()
+	}
+}
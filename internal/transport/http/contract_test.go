@@ -0,0 +1,378 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/attestation"
+	"github.com/dwarvesf/icy-backend/internal/btcrpc"
+	"github.com/dwarvesf/icy-backend/internal/chaos"
+	"github.com/dwarvesf/icy-backend/internal/health"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/openapi"
+	"github.com/dwarvesf/icy-backend/internal/oracle"
+	"github.com/dwarvesf/icy-backend/internal/scheduler"
+	"github.com/dwarvesf/icy-backend/internal/store"
+	"github.com/dwarvesf/icy-backend/internal/stream"
+	"github.com/dwarvesf/icy-backend/internal/types/btcnetworks"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+// contractBtcRpc serves a treasury balance at a fixed block, so reserves can be attested
+type contractBtcRpc struct {
+	btcrpc.IBtcRpc
+}
+
+func (contractBtcRpc) BalanceOf(string) (*model.Web3BigInt, error) {
+	return &model.Web3BigInt{Value: "100000000", Decimal: 8}, nil
+}
+
+func (contractBtcRpc) GetLatestBlock() (*model.BtcBlock, error) {
+	return &model.BtcBlock{Height: 1, Hash: "00"}, nil
+}
+
+// contractOracle serves a recorded price history without a database
+type contractOracle struct {
+	oracle.IOracle
+}
+
+func (contractOracle) GetICYBTCHistory(ctx context.Context, from, to time.Time, interval model.RateInterval) ([]model.IcyBtcRateCandle, error) {
+	return []model.IcyBtcRateCandle{{Time: from, Open: "1", High: "2", Low: "1", Close: "2", Decimal: 18}}, nil
+}
+
+// streamRecorder lets gin stream responses, which needs a CloseNotifier
+type streamRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (streamRecorder) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+// contractRequest is a request whose response must match the spec of route for
+// its status. Requests run in order, so a request can set up the next ones
+type contractRequest struct {
+	method string
+	route  string
+	// path defaults to route
+	path   string
+	apiKey string
+	body   string
+	status int
+}
+
+// loadSpec reads the committed public spec, which must be regenerated when
+// the annotations change (see internal/openapi)
+func loadSpec() *openapi.Document {
+	raw, err := os.ReadFile(filepath.Join("..", "..", "..", openapi.SpecFile))
+	Expect(err).NotTo(HaveOccurred())
+
+	doc := &openapi.Document{}
+	Expect(json.Unmarshal(raw, doc)).To(Succeed())
+
+	return doc
+}
+
+// flatten merges the parts of an allOf into a single object schema, later
+// parts override the properties of earlier ones
+func flatten(schemas map[string]*openapi.Schema, schema *openapi.Schema) *openapi.Schema {
+	if schema.Ref != "" {
+		return flatten(schemas, schemas[schema.RefName()])
+	}
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+
+	merged := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{}}
+	for _, part := range schema.AllOf {
+		part = flatten(schemas, part)
+		for name, property := range part.Properties {
+			merged.Properties[name] = property
+		}
+		merged.Required = append(merged.Required, part.Required...)
+	}
+
+	return merged
+}
+
+// validateAgainstSchema returns the mismatches between a decoded JSON value and its schema
+func validateAgainstSchema(schemas map[string]*openapi.Schema, path string, schema *openapi.Schema, value any) []string {
+	if schema.Ref != "" {
+		definition, ok := schemas[schema.RefName()]
+		if !ok {
+			return []string{fmt.Sprintf("%s: missing schema %s", path, schema.Ref)}
+		}
+		return validateAgainstSchema(schemas, path, definition, value)
+	}
+	schema = flatten(schemas, schema)
+
+	if value == nil {
+		return nil
+	}
+
+	switch schema.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected string, got %T", path, value)}
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return []string{fmt.Sprintf("%s: expected integer, got %v", path, value)}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{fmt.Sprintf("%s: expected number, got %T", path, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected boolean, got %T", path, value)}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array, got %T", path, value)}
+		}
+		if schema.Items == nil {
+			return nil
+		}
+		problems := []string{}
+		for i, item := range items {
+			problems = append(problems, validateAgainstSchema(schemas, fmt.Sprintf("%s[%d]", path, i), schema.Items, item)...)
+		}
+		return problems
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object, got %T", path, value)}
+		}
+		if schema.AdditionalProperties != nil || len(schema.Properties) == 0 {
+			return nil
+		}
+		problems := []string{}
+		for key, fieldValue := range object {
+			property, ok := schema.Properties[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: field is not documented in schema", path, key))
+				continue
+			}
+			problems = append(problems, validateAgainstSchema(schemas, path+"."+key, property, fieldValue)...)
+		}
+		for _, key := range schema.Required {
+			if _, ok := object[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s: required field is missing in response", path, key))
+			}
+		}
+		return problems
+	}
+
+	return nil
+}
+
+var _ = Describe("API contract", func() {
+	var (
+		doc *openapi.Document
+		log *logger.Logger
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		doc = loadSpec()
+		log = logger.New(environments.Test)
+	})
+
+	// newServer serves the full profile, faults are injected through the
+	// admin chaos routes to reach the error responses
+	newServer := func(probes ...health.Probe) (*gin.Engine, chan struct{}) {
+		appConfig := &config.AppConfig{
+			Environment:       environments.Test,
+			DeploymentProfile: profiles.Full,
+			ApiServer:         config.ApiServerConfig{AllowedOrigins: "*"},
+			Bitcoin:           config.BitcoinConfig{Network: btcnetworks.Mainnet, TreasuryAddress: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"},
+			Attestation:       config.AttestationConfig{SigningKey: strings.Repeat("01", 32)},
+			Health:            config.HealthConfig{ProbeTimeout: time.Second},
+			Fee: config.FeeConfig{
+				ServiceFeeTiers: []config.FeeTier{{MinAmount: "0", FeeBps: 100}},
+				MinSatoshiFee:   1000,
+				DustThreshold:   546,
+			},
+		}
+		injector := chaos.New(log)
+		btcRpc := chaos.WrapBtcRpc(contractBtcRpc{}, injector)
+		var o oracle.IOracle = contractOracle{oracle.New(appConfig, log, btcRpc, nil, nil, store.New())}
+		o = chaos.WrapOracle(o, injector)
+
+		// the blocking job stays running until release is closed, so it can't be triggered twice
+		release := make(chan struct{})
+		jobs := scheduler.New(log, nil)
+		Expect(jobs.Register(scheduler.Job{Name: "blocking", Schedule: "@daily", Run: func(ctx context.Context) error {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil
+		}})).To(Succeed())
+		DeferCleanup(jobs.Stop)
+		DeferCleanup(func() {
+			select {
+			case <-release:
+			default:
+				close(release)
+			}
+		})
+
+		keys := staticAuth{
+			"admin-key":     {Name: "admin", Role: model.ApiKeyRoleAdmin},
+			"read-only-key": {Name: "frontend", Role: model.ApiKeyRoleReadOnly},
+		}
+		a := attestation.New(appConfig, log, btcRpc)
+
+		r := NewHttpServer(appConfig, config.NewWatcher(appConfig), log, keys, o, stream.New(appConfig, log, o), health.New(appConfig, log, probes...), a, injector, jobs)
+		return r, release
+	}
+
+	// serve runs the requests and validates every response body, envelope
+	// included, against the spec of its route and status. It returns the
+	// covered "method route status"
+	serve := func(r *gin.Engine, requests []contractRequest) map[string]bool {
+		covered := map[string]bool{}
+		for _, req := range requests {
+			path := req.path
+			if path == "" {
+				path = req.route
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			httpReq := httptest.NewRequest(req.method, path, strings.NewReader(req.body)).WithContext(ctx)
+			if req.apiKey != "" {
+				httpReq.Header.Set("X-API-Key", req.apiKey)
+			}
+			w := streamRecorder{httptest.NewRecorder()}
+			r.ServeHTTP(w, httpReq)
+			cancel()
+			Expect(w.Code).To(Equal(req.status), "%s %s: %s", req.method, path, w.Body.String())
+
+			op := doc.Paths[req.route][strings.ToLower(req.method)]
+			Expect(op).NotTo(BeNil(), "%s %s is not documented", req.method, req.route)
+			response, ok := op.Responses[fmt.Sprint(req.status)]
+			Expect(ok).To(BeTrue(), "%s %s: status %d is not documented", req.method, req.route, req.status)
+			covered[fmt.Sprintf("%s %s %d", strings.ToLower(req.method), req.route, req.status)] = true
+
+			for contentType, media := range response.Content {
+				Expect(w.Header().Get("Content-Type")).To(HavePrefix(contentType), "%s %s", req.method, path)
+				if contentType != "application/json" {
+					continue
+				}
+				var decoded any
+				Expect(json.Unmarshal(w.Body.Bytes(), &decoded)).To(Succeed(), "%s %s", req.method, path)
+				Expect(validateAgainstSchema(doc.Components.Schemas, req.method+" "+path, media.Schema, decoded)).To(BeEmpty())
+			}
+		}
+		return covered
+	}
+
+	// publicRequests are served by every API version, the oracle and attestation
+	// requests fail once faults are injected
+	publicRequests := func(version string, oracleStatus, attestationStatus int) []contractRequest {
+		prefix := "/api/" + version
+		requests := []contractRequest{}
+		for _, route := range []string{"/oracle/circulated-icy", "/oracle/circulated-icy-cached", "/oracle/treasury-btc", "/oracle/treasury-btc-cached", "/oracle/icy-btc-ratio", "/oracle/icy-btc-ratio-cached"} {
+			requests = append(requests, contractRequest{method: http.MethodGet, route: prefix + route, status: oracleStatus})
+		}
+		return append(requests,
+			contractRequest{method: http.MethodGet, route: prefix + "/rates/history", path: prefix + "/rates/history?interval=day", status: oracleStatus},
+			contractRequest{method: http.MethodGet, route: prefix + "/attestation/reserves", status: attestationStatus},
+		)
+	}
+
+	It("should serve every documented route and status as specified", func() {
+		requests := []contractRequest{
+			{method: http.MethodGet, route: "/healthz", status: http.StatusOK},
+			{method: http.MethodGet, route: "/readyz", status: http.StatusOK},
+		}
+		for _, version := range []string{"v1", "v2"} {
+			prefix := "/api/" + version
+			requests = append(requests, publicRequests(version, http.StatusOK, http.StatusOK)...)
+			requests = append(requests,
+				contractRequest{method: http.MethodGet, route: prefix + "/fees", status: http.StatusOK},
+				contractRequest{method: http.MethodGet, route: prefix + "/stream", status: http.StatusOK},
+				contractRequest{method: http.MethodGet, route: prefix + "/rates/history", path: prefix + "/rates/history?interval=week", status: http.StatusBadRequest},
+			)
+		}
+
+		chaos := "/api/v1/admin/chaos/{target}"
+		jobs := "/api/v1/admin/jobs"
+		job := "/api/v1/admin/jobs/{name}"
+		run := "/api/v1/admin/jobs/{name}/run"
+		requests = append(requests,
+			contractRequest{method: http.MethodGet, route: "/api/v1/admin/chaos", status: http.StatusUnauthorized},
+			contractRequest{method: http.MethodGet, route: "/api/v1/admin/chaos", apiKey: "read-only-key", status: http.StatusForbidden},
+			contractRequest{method: http.MethodPut, route: chaos, path: "/api/v1/admin/chaos/oracle", status: http.StatusUnauthorized},
+			contractRequest{method: http.MethodPut, route: chaos, path: "/api/v1/admin/chaos/oracle", apiKey: "read-only-key", status: http.StatusForbidden},
+			contractRequest{method: http.MethodPut, route: chaos, path: "/api/v1/admin/chaos/oracle", apiKey: "admin-key", body: `{"error_rate":2}`, status: http.StatusBadRequest},
+			contractRequest{method: http.MethodPut, route: chaos, path: "/api/v1/admin/chaos/oracle", apiKey: "admin-key", body: `{"error_rate":1}`, status: http.StatusOK},
+			contractRequest{method: http.MethodPut, route: chaos, path: "/api/v1/admin/chaos/btcrpc", apiKey: "admin-key", body: `{"error_rate":1}`, status: http.StatusOK},
+			contractRequest{method: http.MethodGet, route: "/api/v1/admin/chaos", apiKey: "admin-key", status: http.StatusOK},
+		)
+		for _, version := range []string{"v1", "v2"} {
+			requests = append(requests, publicRequests(version, http.StatusInternalServerError, http.StatusInternalServerError)...)
+		}
+		requests = append(requests,
+			contractRequest{method: http.MethodDelete, route: chaos, path: "/api/v1/admin/chaos/oracle", status: http.StatusUnauthorized},
+			contractRequest{method: http.MethodDelete, route: chaos, path: "/api/v1/admin/chaos/oracle", apiKey: "read-only-key", status: http.StatusForbidden},
+			contractRequest{method: http.MethodDelete, route: chaos, path: "/api/v1/admin/chaos/unknown", apiKey: "admin-key", status: http.StatusBadRequest},
+			contractRequest{method: http.MethodDelete, route: chaos, path: "/api/v1/admin/chaos/oracle", apiKey: "admin-key", status: http.StatusOK},
+
+			contractRequest{method: http.MethodGet, route: jobs, status: http.StatusUnauthorized},
+			contractRequest{method: http.MethodGet, route: jobs, apiKey: "read-only-key", status: http.StatusForbidden},
+			contractRequest{method: http.MethodGet, route: job, path: jobs + "/blocking", status: http.StatusUnauthorized},
+			contractRequest{method: http.MethodGet, route: job, path: jobs + "/blocking", apiKey: "read-only-key", status: http.StatusForbidden},
+			contractRequest{method: http.MethodGet, route: job, path: jobs + "/unknown", apiKey: "admin-key", status: http.StatusNotFound},
+			contractRequest{method: http.MethodPost, route: run, path: jobs + "/blocking/run", status: http.StatusUnauthorized},
+			contractRequest{method: http.MethodPost, route: run, path: jobs + "/blocking/run", apiKey: "read-only-key", status: http.StatusForbidden},
+			contractRequest{method: http.MethodPost, route: run, path: jobs + "/unknown/run", apiKey: "admin-key", status: http.StatusNotFound},
+			contractRequest{method: http.MethodPost, route: run, path: jobs + "/blocking/run", apiKey: "admin-key", status: http.StatusAccepted},
+			contractRequest{method: http.MethodPost, route: run, path: jobs + "/blocking/run", apiKey: "admin-key", status: http.StatusConflict},
+			// the running job fills in the optional fields of its status
+			contractRequest{method: http.MethodGet, route: jobs, apiKey: "admin-key", status: http.StatusOK},
+			contractRequest{method: http.MethodGet, route: job, path: jobs + "/blocking", apiKey: "admin-key", status: http.StatusOK},
+		)
+
+		r, _ := newServer()
+		covered := serve(r, requests)
+
+		unavailable, _ := newServer(health.Probe{Name: "postgres", Check: func(context.Context) error { return errors.New("down") }})
+		for key := range serve(unavailable, []contractRequest{{method: http.MethodGet, route: "/readyz", status: http.StatusServiceUnavailable}}) {
+			covered[key] = true
+		}
+
+		missing := []string{}
+		for path, item := range doc.Paths {
+			for method, op := range item {
+				for status := range op.Responses {
+					if key := method + " " + path + " " + status; !covered[key] {
+						missing = append(missing, key)
+					}
+				}
+			}
+		}
+		sort.Strings(missing)
+		Expect(missing).To(BeEmpty(), "add requests for the documented routes and statuses, or stop documenting the unreachable ones")
+	})
+})
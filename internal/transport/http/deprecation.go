@@ -0,0 +1,25 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deprecated announces that the routes it wraps are slated for removal on
+// sunset (Deprecation and Sunset headers, RFC 8594), and links the same
+// route under successorBase
+func deprecated(sunset time.Time, base, successorBase string) gin.HandlerFunc {
+	sunsetHeader := sunset.UTC().Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Sunset", sunsetHeader)
+		if path := c.Request.URL.Path; strings.HasPrefix(path, base) {
+			c.Header("Link", "<"+successorBase+strings.TrimPrefix(path, base)+`>; rel="successor-version"`)
+		}
+		c.Next()
+	}
+}
@@ -1,13 +1,22 @@
 package http
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
+	_ "github.com/dwarvesf/icy-backend/docs" // generated swagger spec served at /swagger
+	"github.com/dwarvesf/icy-backend/internal/attestation"
+	"github.com/dwarvesf/icy-backend/internal/auth"
+	"github.com/dwarvesf/icy-backend/internal/chaos"
 	"github.com/dwarvesf/icy-backend/internal/handler"
+	"github.com/dwarvesf/icy-backend/internal/health"
 	"github.com/dwarvesf/icy-backend/internal/oracle"
+	"github.com/dwarvesf/icy-backend/internal/scheduler"
+	"github.com/dwarvesf/icy-backend/internal/stream"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
 	swaggerFiles "github.com/swaggo/files"     // swagger embed files
@@ -23,7 +32,7 @@ func setupCORS(r *gin.Engine, cfg *config.AppConfig) {
 				AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "HEAD"},
 				AllowHeaders: []string{
 					"Origin", "Host", "Content-Type", "Content-Length", "Accept-Encoding", "Accept-Language", "Accept",
-					"X-CSRF-Token", "Authorization", "X-Requested-With", "X-Access-Token",
+					"X-CSRF-Token", "Authorization", "X-Requested-With", "X-Access-Token", "X-API-Key",
 				},
 				AllowCredentials: true,
 			},
@@ -31,21 +40,50 @@ func setupCORS(r *gin.Engine, cfg *config.AppConfig) {
 	})
 }
 
-func NewHttpServer(appConfig *config.AppConfig, logger *logger.Logger, oracle oracle.IOracle) *gin.Engine {
+// readOnlyGuard rejects every request that could change state, it backs up the
+// route registration in the public read-only profile
+func readOnlyGuard(c *gin.Context) {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+	default:
+		c.AbortWithStatusJSON(http.StatusMethodNotAllowed, gin.H{
+			"error": "read-only deployment",
+		})
+	}
+}
+
+func NewHttpServer(appConfig *config.AppConfig, configWatcher *config.Watcher, logger *logger.Logger, auth auth.IAuth, oracle oracle.IOracle, streamHub stream.IHub, health health.IHealth, attestation attestation.IAttestation, chaos chaos.IChaos, scheduler scheduler.IScheduler) *gin.Engine {
 	r := gin.New()
+	// X-Forwarded-For is only read from trusted proxies, otherwise any client
+	// could pick its own IP and escape the per IP rate limit
+	if err := r.SetTrustedProxies(appConfig.ApiServer.TrustedProxies); err != nil {
+		logger.Error("invalid trusted proxies, trusting none", map[string]string{"error": err.Error()})
+		r.SetTrustedProxies(nil)
+	}
 	r.Use(
-		gin.LoggerWithWriter(gin.DefaultWriter, "/healthz"),
+		gin.LoggerWithWriter(gin.DefaultWriter, "/healthz", "/readyz"),
 		gin.Recovery(),
 	)
 	setupCORS(r, appConfig)
+	if appConfig.DeploymentProfile == profiles.PublicReadOnly {
+		r.Use(readOnlyGuard)
+	}
 
-	h := handler.New(appConfig, logger, oracle)
+	h := handler.New(appConfig, logger, oracle, streamHub, health, attestation, chaos, scheduler)
+	configWatcher.Subscribe(func(_, new *config.AppConfig) {
+		h.FeeHandler.SetFeeConfig(new.Fee)
+	})
 
 	// use ginSwagger middleware to serve the API docs
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// both versions share the rate limit buckets
+	publicLimit, expensiveLimit, apiKeyLookupLimit := newRateLimiters(appConfig.RateLimit, configWatcher)
+
 	// load api
-	loadV1Routes(r, h, appConfig, logger)
+	loadV1Routes(r, h, auth, appConfig, publicLimit, expensiveLimit, apiKeyLookupLimit, logger)
+	loadV2Routes(r, h, auth, publicLimit, expensiveLimit, apiKeyLookupLimit, logger)
 
 	return r
 }
@@ -0,0 +1,295 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+
+	"github.com/dwarvesf/icy-backend/internal/auth"
+	"github.com/dwarvesf/icy-backend/internal/btcrpc"
+	"github.com/dwarvesf/icy-backend/internal/chaos"
+	"github.com/dwarvesf/icy-backend/internal/health"
+	"github.com/dwarvesf/icy-backend/internal/model"
+	"github.com/dwarvesf/icy-backend/internal/oracle"
+	"github.com/dwarvesf/icy-backend/internal/scheduler"
+	"github.com/dwarvesf/icy-backend/internal/store"
+	"github.com/dwarvesf/icy-backend/internal/stream"
+	"github.com/dwarvesf/icy-backend/internal/types/btcnetworks"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+var _ = Describe("HttpServer", func() {
+	var (
+		appConfig *config.AppConfig
+		log       *logger.Logger
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		appConfig = &config.AppConfig{
+			Environment:       environments.Test,
+			DeploymentProfile: profiles.PublicReadOnly,
+			ApiServer:         config.ApiServerConfig{AllowedOrigins: "*"},
+		}
+		log = logger.New(environments.Test)
+	})
+
+	Describe("public read-only profile", func() {
+		var r *gin.Engine
+
+		BeforeEach(func() {
+			btcRpc := btcrpc.NewReadOnly(btcrpc.New(appConfig, log))
+			o := oracle.New(appConfig, log, btcRpc, nil, nil, store.New())
+			r = NewHttpServer(appConfig, config.NewWatcher(appConfig), log, nil, o, stream.New(appConfig, log, o), health.New(appConfig, log), nil, nil, nil)
+		})
+
+		It("should only register read routes", func() {
+			for _, route := range r.Routes() {
+				Expect(route.Method).To(BeElementOf(http.MethodGet, http.MethodHead), route.Path)
+				Expect(route.Path).NotTo(ContainSubstring("/admin"))
+				Expect(route.Path).NotTo(ContainSubstring("/attestation"))
+			}
+		})
+
+		It("should reject write requests", func() {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/chaos/btcrpc", strings.NewReader("{}"))
+			r.ServeHTTP(w, req)
+
+			Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("should still serve read endpoints", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/oracle/circulated-icy", nil))
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should serve the generated swagger spec", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil))
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring("/api/v1/oracle/circulated-icy"))
+		})
+
+		It("should refuse BTC sends", func() {
+			btcRpc := btcrpc.NewReadOnly(btcrpc.New(appConfig, log))
+			err := btcRpc.Send("bc1q", &model.Web3BigInt{Value: "1", Decimal: 8})
+
+			Expect(err).To(MatchError(btcrpc.ErrReadOnly))
+		})
+	})
+
+	Describe("full profile", func() {
+		It("should register admin routes when chaos is enabled", func() {
+			appConfig.DeploymentProfile = profiles.Full
+			btcRpc := btcrpc.New(appConfig, log)
+			o := oracle.New(appConfig, log, btcRpc, nil, nil, store.New())
+			r := NewHttpServer(appConfig, config.NewWatcher(appConfig), log, auth.New(appConfig, log, nil, store.New()), o, stream.New(appConfig, log, o), health.New(appConfig, log), nil, chaos.New(log), nil)
+
+			paths := []string{}
+			for _, route := range r.Routes() {
+				paths = append(paths, route.Method+" "+route.Path)
+			}
+			Expect(paths).To(ContainElement("PUT /api/v1/admin/chaos/:target"))
+		})
+
+		It("should reject admin requests without an API key", func() {
+			appConfig.DeploymentProfile = profiles.Full
+			btcRpc := btcrpc.New(appConfig, log)
+			o := oracle.New(appConfig, log, btcRpc, nil, nil, store.New())
+			r := NewHttpServer(appConfig, config.NewWatcher(appConfig), log, auth.New(appConfig, log, nil, store.New()), o, stream.New(appConfig, log, o), health.New(appConfig, log), nil, chaos.New(log), nil)
+
+			// fault injection must never be reachable without an admin key
+			for _, req := range []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/api/v1/admin/chaos", nil),
+				httptest.NewRequest(http.MethodPut, "/api/v1/admin/chaos/btcrpc", strings.NewReader(`{"error_rate":1}`)),
+				httptest.NewRequest(http.MethodDelete, "/api/v1/admin/chaos/btcrpc", nil),
+			} {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnauthorized), req.Method+" "+req.URL.Path)
+			}
+		})
+
+		It("should let operators run jobs but not inject faults", func() {
+			appConfig.DeploymentProfile = profiles.Full
+			btcRpc := btcrpc.New(appConfig, log)
+			o := oracle.New(appConfig, log, btcRpc, nil, nil, store.New())
+			jobs := scheduler.New(log, nil)
+			defer jobs.Stop()
+			Expect(jobs.Register(scheduler.Job{Name: "noop", Schedule: "@yearly", Run: func(context.Context) error { return nil }})).To(Succeed())
+			keys := staticAuth{"operator-key": {Name: "ops", Role: model.ApiKeyRoleOperator}}
+			r := NewHttpServer(appConfig, config.NewWatcher(appConfig), log, keys, o, stream.New(appConfig, log, o), health.New(appConfig, log), nil, chaos.New(log), jobs)
+
+			for _, tc := range []struct {
+				req  *http.Request
+				code int
+			}{
+				{httptest.NewRequest(http.MethodGet, "/api/v1/admin/jobs", nil), http.StatusOK},
+				{httptest.NewRequest(http.MethodPost, "/api/v1/admin/jobs/noop/run", nil), http.StatusAccepted},
+				{httptest.NewRequest(http.MethodGet, "/api/v1/admin/chaos", nil), http.StatusForbidden},
+				{httptest.NewRequest(http.MethodPut, "/api/v1/admin/chaos/btcrpc", strings.NewReader(`{"error_rate":1}`)), http.StatusForbidden},
+			} {
+				tc.req.Header.Set("X-API-Key", "operator-key")
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, tc.req)
+				Expect(w.Code).To(Equal(tc.code), tc.req.Method+" "+tc.req.URL.Path)
+			}
+		})
+
+		It("should validate and record but not broadcast BTC sends in dry-run mode", func() {
+			appConfig.Bitcoin.Network = btcnetworks.Mainnet
+			recorded := &recordingDryRunTxStore{}
+			btcRpc := btcrpc.NewDryRun(btcrpc.New(appConfig, log), appConfig, log, nil, &store.Store{DryRunTx: recorded})
+
+			Expect(btcRpc.Send("bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", &model.Web3BigInt{Value: "1", Decimal: 8})).To(Succeed())
+			Expect(btcRpc.Send("bc1q", &model.Web3BigInt{Value: "1", Decimal: 8})).NotTo(Succeed())
+			Expect(btcRpc.Send("bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", nil)).To(MatchError(btcrpc.ErrMissingAmount))
+
+			Expect(recorded.txs).To(HaveLen(1))
+			Expect(recorded.txs[0].ReceiverAddress).To(Equal("bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"))
+			Expect(recorded.txs[0].Value).To(Equal("1"))
+			Expect(recorded.txs[0].Network).To(Equal(string(btcnetworks.Mainnet)))
+		})
+	})
+
+	Describe("rate limiting", func() {
+		var r *gin.Engine
+
+		BeforeEach(func() {
+			appConfig.RateLimit = config.RateLimitConfig{Enabled: true, PublicRPS: 0.001, PublicBurst: 1, ExpensiveRPS: 0.001, ExpensiveBurst: 1}
+			btcRpc := btcrpc.New(appConfig, log)
+			o := oracle.New(appConfig, log, btcRpc, nil, nil, store.New())
+			r = NewHttpServer(appConfig, config.NewWatcher(appConfig), log, auth.New(appConfig, log, nil, store.New()), o, stream.New(appConfig, log, o), health.New(appConfig, log), nil, nil, nil)
+		})
+
+		request := func(remoteAddr, forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/fees", nil)
+			req.RemoteAddr = remoteAddr
+			if forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		It("should limit API key lookups per IP before authenticating them", func() {
+			lookups := 0
+			keys := countingAuth(func() { lookups++ })
+			btcRpc := btcrpc.New(appConfig, log)
+			o := oracle.New(appConfig, log, btcRpc, nil, nil, store.New())
+			r = NewHttpServer(appConfig, config.NewWatcher(appConfig), log, keys, o, stream.New(appConfig, log, o), health.New(appConfig, log), nil, nil, nil)
+
+			codes := []int{}
+			for _, key := range []string{"made-up-1", "made-up-2", "made-up-3"} {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/fees", nil)
+				req.RemoteAddr = "203.0.113.7:1234"
+				req.Header.Set("X-API-Key", key)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				codes = append(codes, w.Code)
+			}
+
+			Expect(codes).To(Equal([]int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests}))
+			Expect(lookups).To(Equal(1))
+			// anonymous requests of the same IP keep their own bucket
+			Expect(request("203.0.113.7:1234", "")).To(Equal(http.StatusOK))
+		})
+
+		It("should count a spoofed X-Forwarded-For against the caller's address", func() {
+			Expect(request("203.0.113.7:1234", "198.51.100.1")).To(Equal(http.StatusOK))
+			Expect(request("203.0.113.7:1234", "198.51.100.2")).To(Equal(http.StatusTooManyRequests))
+			Expect(request("203.0.113.8:1234", "")).To(Equal(http.StatusOK))
+		})
+	})
+
+	Describe("versioning", func() {
+		newServer := func() *gin.Engine {
+			btcRpc := btcrpc.New(appConfig, log)
+			o := oracle.New(appConfig, log, btcRpc, nil, nil, store.New())
+			return NewHttpServer(appConfig, config.NewWatcher(appConfig), log, auth.New(appConfig, log, nil, store.New()), o, stream.New(appConfig, log, o), health.New(appConfig, log), nil, nil, nil)
+		}
+
+		It("should serve the public routes under v2", func() {
+			w := httptest.NewRecorder()
+			newServer().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/oracle/circulated-icy", nil))
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+		})
+
+		It("should not mark v1 as deprecated without a sunset", func() {
+			w := httptest.NewRecorder()
+			newServer().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/oracle/circulated-icy", nil))
+
+			Expect(w.Header().Get("Deprecation")).To(BeEmpty())
+			Expect(w.Header().Get("Sunset")).To(BeEmpty())
+		})
+
+		It("should announce the v1 sunset and link the v2 route", func() {
+			appConfig.ApiServer.V1Sunset = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+			r := newServer()
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/oracle/circulated-icy", nil))
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Deprecation")).To(Equal("true"))
+			Expect(w.Header().Get("Sunset")).To(Equal("Thu, 01 Jan 2026 00:00:00 GMT"))
+			Expect(w.Header().Get("Link")).To(Equal(`</api/v2/oracle/circulated-icy>; rel="successor-version"`))
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/oracle/circulated-icy", nil))
+			Expect(w.Header().Get("Deprecation")).To(BeEmpty())
+		})
+	})
+})
+
+type recordingDryRunTxStore struct {
+	txs []*model.DryRunTransaction
+}
+
+func (s *recordingDryRunTxStore) Create(ctx context.Context, db *gorm.DB, tx *model.DryRunTransaction) error {
+	s.txs = append(s.txs, tx)
+	return nil
+}
+
+// countingAuth rejects every API key and calls lookup for each of them
+type countingAuth func()
+
+func (a countingAuth) Authenticate(ctx context.Context, rawKey string) (*model.ApiKey, error) {
+	a()
+	return nil, auth.ErrInvalidApiKey
+}
+
+func (a countingAuth) CreateApiKey(ctx context.Context, name string, role model.ApiKeyRole) (string, *model.ApiKey, error) {
+	return "", nil, errors.New("not supported")
+}
+
+// staticAuth authenticates the API keys it holds, keyed by raw key
+type staticAuth map[string]*model.ApiKey
+
+func (a staticAuth) Authenticate(ctx context.Context, rawKey string) (*model.ApiKey, error) {
+	if apiKey, ok := a[rawKey]; ok {
+		return apiKey, nil
+	}
+	return nil, auth.ErrInvalidApiKey
+}
+
+func (a staticAuth) CreateApiKey(ctx context.Context, name string, role model.ApiKeyRole) (string, *model.ApiKey, error) {
+	return "", nil, errors.New("not supported")
+}
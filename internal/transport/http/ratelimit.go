@@ -0,0 +1,80 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/dwarvesf/icy-backend/internal/ratelimit"
+	"github.com/dwarvesf/icy-backend/internal/utils/config"
+	"github.com/dwarvesf/icy-backend/internal/view"
+)
+
+// ContextKeyRateLimitKey is set by authentication middlewares to rate limit
+// per API key instead of per IP
+const ContextKeyRateLimitKey = "rate_limit_key"
+
+func rateLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if apiKey := c.GetString(ContextKeyRateLimitKey); apiKey != "" {
+			key = "key:" + apiKey
+		}
+
+		if allow(c, limiter, key) {
+			c.Next()
+		}
+	}
+}
+
+// apiKeyLookupLimitMiddleware limits per IP the requests carrying an API key,
+// it runs before authentication so made up keys can't query the database unthrottled
+func apiKeyLookupLimitMiddleware(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(apiKeyHeader) == "" {
+			c.Next()
+			return
+		}
+
+		if allow(c, limiter, "ip:"+c.ClientIP()) {
+			c.Next()
+		}
+	}
+}
+
+// allow takes a token of key, it aborts the request with 429 when there is none left
+func allow(c *gin.Context, limiter *ratelimit.Limiter, key string) bool {
+	allowed, retryAfter := limiter.Allow(key)
+	if !allowed {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests,
+			view.CreateErrorResponse(nil, nil, view.ErrCodeRateLimited, c.GetHeader("Accept-Language")))
+		return false
+	}
+
+	return true
+}
+
+// newRateLimiters returns the middlewares for cheap public reads, for expensive
+// endpoints and for API key lookups, all are no-op when rate limiting is
+// disabled. API key lookups get the public limits in their own per IP buckets.
+// The limits follow config reloads, enabling or disabling rate limiting needs a restart
+func newRateLimiters(rl config.RateLimitConfig, configWatcher *config.Watcher) (gin.HandlerFunc, gin.HandlerFunc, gin.HandlerFunc) {
+	if !rl.Enabled {
+		noop := func(c *gin.Context) { c.Next() }
+		return noop, noop, noop
+	}
+
+	public := ratelimit.New(rl.PublicRPS, rl.PublicBurst)
+	expensive := ratelimit.New(rl.ExpensiveRPS, rl.ExpensiveBurst)
+	apiKeyLookup := ratelimit.New(rl.PublicRPS, rl.PublicBurst)
+	configWatcher.Subscribe(func(_, new *config.AppConfig) {
+		public.SetRate(new.RateLimit.PublicRPS, new.RateLimit.PublicBurst)
+		expensive.SetRate(new.RateLimit.ExpensiveRPS, new.RateLimit.ExpensiveBurst)
+		apiKeyLookup.SetRate(new.RateLimit.PublicRPS, new.RateLimit.PublicBurst)
+	})
+
+	return rateLimitMiddleware(public), rateLimitMiddleware(expensive), apiKeyLookupLimitMiddleware(apiKeyLookup)
+}
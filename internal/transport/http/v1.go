@@ -3,27 +3,71 @@ package http
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/dwarvesf/icy-backend/internal/auth"
 	"github.com/dwarvesf/icy-backend/internal/handler"
+	"github.com/dwarvesf/icy-backend/internal/model"
 	"github.com/dwarvesf/icy-backend/internal/utils/config"
 	"github.com/dwarvesf/icy-backend/internal/utils/logger"
 )
 
-func loadV1Routes(r *gin.Engine, h *handler.Handler, appConfig *config.AppConfig, logger *logger.Logger) {
+func loadV1Routes(r *gin.Engine, h *handler.Handler, auth auth.IAuth, appConfig *config.AppConfig, publicLimit, expensiveLimit, apiKeyLookupLimit gin.HandlerFunc, logger *logger.Logger) {
 
-	v1 := r.Group("/api/v1")
+	// API keys are optional on public routes, they only switch rate limiting to per key
+	v1 := r.Group("/api/v1", apiKeyLookupLimit, optionalApiKey(auth, logger))
+	// every admin route group requires a role, operators run jobs, fault injection is admin only
+	admin := v1.Group("/admin")
 
-	oracle := v1.Group("/oracle")
+	public := v1.Group("")
+	if !appConfig.ApiServer.V1Sunset.IsZero() {
+		public.Use(deprecated(appConfig.ApiServer.V1Sunset, "/api/v1", "/api/v2"))
+	}
+	loadPublicRoutes(public, h, publicLimit, expensiveLimit)
+
+	if h.ChaosHandler != nil {
+		chaos := admin.Group("/chaos", requireRole(auth, logger, model.ApiKeyRoleAdmin))
+		{
+			chaos.GET("", h.ChaosHandler.ListFaults)
+			chaos.PUT("/:target", h.ChaosHandler.SetFault)
+			chaos.DELETE("/:target", h.ChaosHandler.ClearFault)
+		}
+	}
+
+	if h.JobHandler != nil {
+		jobs := admin.Group("/jobs", requireRole(auth, logger, model.ApiKeyRoleOperator))
+		{
+			jobs.GET("", h.JobHandler.ListJobs)
+			jobs.GET("/:name", h.JobHandler.GetJob)
+			jobs.POST("/:name/run", h.JobHandler.TriggerJob)
+		}
+	}
+
+	// health check
+	r.GET("/healthz", h.HealthHandler.Liveness)
+	r.GET("/readyz", h.HealthHandler.Readiness)
+}
+
+// loadPublicRoutes registers the read routes served by every API version
+func loadPublicRoutes(rg *gin.RouterGroup, h *handler.Handler, publicLimit, expensiveLimit gin.HandlerFunc) {
+	oracle := rg.Group("/oracle", publicLimit)
 	{
 		oracle.GET("/circulated-icy", h.OracleHandler.GetCirculatedICY)
+		oracle.GET("/circulated-icy-cached", h.OracleHandler.GetCirculatedICYCached)
 		oracle.GET("/treasury-btc", h.OracleHandler.GetTreasusyBTC)
+		oracle.GET("/treasury-btc-cached", h.OracleHandler.GetTreasuryBTCCached)
 		oracle.GET("/icy-btc-ratio", h.OracleHandler.GetICYBTCRatio)
 		oracle.GET("/icy-btc-ratio-cached", h.OracleHandler.GetICYBTCRatioCached)
 	}
 
-	// health check
-	r.GET("/healthz", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "ok",
-		})
-	})
+	// history aggregates the stored prices on every call
+	rg.GET("/rates/history", expensiveLimit, h.OracleHandler.GetICYBTCRateHistory)
+	rg.GET("/fees", publicLimit, h.FeeHandler.GetFeeSchedule)
+	rg.GET("/stream", publicLimit, h.StreamHandler.StreamUpdates)
+
+	if h.AttestationHandler != nil {
+		// every attestation is signed, keep it in the expensive bucket
+		attestation := rg.Group("/attestation", expensiveLimit)
+		{
+			attestation.GET("/reserves", h.AttestationHandler.GetReserveAttestation)
+		}
+	}
 }
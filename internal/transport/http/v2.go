@@ -0,0 +1,18 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/dwarvesf/icy-backend/internal/auth"
+	"github.com/dwarvesf/icy-backend/internal/handler"
+	"github.com/dwarvesf/icy-backend/internal/utils/logger"
+)
+
+// loadV2Routes registers the v2 API. It starts with the v1 public routes, a
+// route whose response shape changes gets its own v2 handler here while v1
+// keeps serving the old shape until its sunset
+func loadV2Routes(r *gin.Engine, h *handler.Handler, auth auth.IAuth, publicLimit, expensiveLimit, apiKeyLookupLimit gin.HandlerFunc, logger *logger.Logger) {
+	v2 := r.Group("/api/v2", apiKeyLookupLimit, optionalApiKey(auth, logger))
+
+	loadPublicRoutes(v2, h, publicLimit, expensiveLimit)
+}
@@ -0,0 +1,9 @@
+package btcnetworks
+
+type Network string
+
+const (
+	Mainnet Network = "mainnet"
+	Testnet Network = "testnet"
+	Regtest Network = "regtest"
+)
@@ -0,0 +1,10 @@
+package profiles
+
+type Profile string
+
+const (
+	// Full runs every subsystem, including signing, payouts and admin routes
+	Full Profile = "full"
+	// PublicReadOnly only serves read endpoints and loads no keys
+	PublicReadOnly Profile = "public-readonly"
+)
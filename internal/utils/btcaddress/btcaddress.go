@@ -0,0 +1,212 @@
+package btcaddress
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dwarvesf/icy-backend/internal/types/btcnetworks"
+)
+
+var ErrInvalid = errors.New("invalid BTC address")
+
+type networkParams struct {
+	bech32HRP     string
+	pubKeyHashVer byte
+	scriptHashVer byte
+}
+
+var networks = map[btcnetworks.Network]networkParams{
+	btcnetworks.Mainnet: {bech32HRP: "bc", pubKeyHashVer: 0x00, scriptHashVer: 0x05},
+	btcnetworks.Testnet: {bech32HRP: "tb", pubKeyHashVer: 0x6f, scriptHashVer: 0xc4},
+	btcnetworks.Regtest: {bech32HRP: "bcrt", pubKeyHashVer: 0x6f, scriptHashVer: 0xc4},
+}
+
+// Validate checks that address is a P2PKH, P2SH, segwit (bech32) or
+// taproot (bech32m) address of the given network
+func Validate(address string, network btcnetworks.Network) error {
+	params, ok := networks[network]
+	if !ok {
+		return fmt.Errorf("unknown BTC network %q", network)
+	}
+
+	hrp, _, hasSeparator := strings.Cut(strings.ToLower(address), "1")
+	if hasSeparator && (hrp == "bc" || hrp == "tb" || hrp == "bcrt") {
+		if hrp != params.bech32HRP {
+			return fmt.Errorf("%w: not a %s address", ErrInvalid, network)
+		}
+		return validateSegwitAddress(address, params.bech32HRP)
+	}
+
+	return validateBase58Address(address, params)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func validateBase58Address(address string, params networkParams) error {
+	decoded, err := decodeBase58(address)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != 25 {
+		return fmt.Errorf("%w: invalid length", ErrInvalid)
+	}
+
+	payload, checksum := decoded[:21], decoded[21:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], checksum) {
+		return fmt.Errorf("%w: invalid checksum", ErrInvalid)
+	}
+
+	if payload[0] != params.pubKeyHashVer && payload[0] != params.scriptHashVer {
+		return fmt.Errorf("%w: wrong network or address type", ErrInvalid)
+	}
+
+	return nil
+}
+
+func decodeBase58(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty address", ErrInvalid)
+	}
+
+	// big-endian base256 number, built digit by digit
+	num := []byte{}
+	for _, r := range s {
+		digit := strings.IndexRune(base58Alphabet, r)
+		if digit < 0 {
+			return nil, fmt.Errorf("%w: invalid base58 character %q", ErrInvalid, r)
+		}
+
+		carry := digit
+		for i := len(num) - 1; i >= 0; i-- {
+			carry += int(num[i]) * 58
+			num[i] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			num = append([]byte{byte(carry)}, num...)
+			carry >>= 8
+		}
+	}
+
+	// every leading '1' is a leading zero byte
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), num...), nil
+}
+
+const (
+	bech32Charset    = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32Const      = 1
+	bech32mConst     = 0x2bc830a3
+	bech32MaxLength  = 90
+	bech32ChecksumSz = 6
+)
+
+// validateSegwitAddress decodes a BIP173/BIP350 address and checks its witness program
+func validateSegwitAddress(address, expectedHRP string) error {
+	if len(address) > bech32MaxLength {
+		return fmt.Errorf("%w: too long", ErrInvalid)
+	}
+	if address != strings.ToLower(address) && address != strings.ToUpper(address) {
+		return fmt.Errorf("%w: mixed case", ErrInvalid)
+	}
+	address = strings.ToLower(address)
+
+	sep := strings.LastIndexByte(address, '1')
+	hrp, dataPart := address[:sep], address[sep+1:]
+	if hrp != expectedHRP || len(dataPart) < bech32ChecksumSz+1 {
+		return fmt.Errorf("%w: malformed bech32", ErrInvalid)
+	}
+
+	data := make([]byte, len(dataPart))
+	for i := range dataPart {
+		v := strings.IndexByte(bech32Charset, dataPart[i])
+		if v < 0 {
+			return fmt.Errorf("%w: invalid bech32 character %q", ErrInvalid, dataPart[i])
+		}
+		data[i] = byte(v)
+	}
+
+	witnessVersion := data[0]
+	expectedConst := uint32(bech32Const)
+	if witnessVersion > 0 {
+		expectedConst = bech32mConst
+	}
+	if bech32Polymod(hrp, data) != expectedConst {
+		return fmt.Errorf("%w: invalid checksum", ErrInvalid)
+	}
+
+	if witnessVersion > 16 {
+		return fmt.Errorf("%w: invalid witness version", ErrInvalid)
+	}
+	program, err := convertBits(data[1:len(data)-bech32ChecksumSz], 5, 8)
+	if err != nil {
+		return err
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return fmt.Errorf("%w: invalid witness program length", ErrInvalid)
+	}
+	if witnessVersion == 0 && len(program) != 20 && len(program) != 32 {
+		return fmt.Errorf("%w: invalid witness program length", ErrInvalid)
+	}
+
+	return nil
+}
+
+func bech32Polymod(hrp string, data []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	values := make([]byte, 0, len(hrp)*2+1+len(data))
+	for i := range hrp {
+		values = append(values, hrp[i]>>5)
+	}
+	values = append(values, 0)
+	for i := range hrp {
+		values = append(values, hrp[i]&31)
+	}
+	values = append(values, data...)
+
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+// convertBits regroups fromBits-wide values into toBits-wide ones, rejecting
+// non-zero padding as BIP173 requires
+func convertBits(data []byte, fromBits, toBits uint) ([]byte, error) {
+	acc, bits := uint32(0), uint(0)
+	maxValue := uint32(1)<<toBits - 1
+	out := []byte{}
+
+	for _, v := range data {
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxValue))
+		}
+	}
+
+	if bits >= fromBits || (acc<<(toBits-bits))&maxValue != 0 {
+		return nil, fmt.Errorf("%w: invalid padding", ErrInvalid)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,13 @@
+package btcaddress
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBtcaddress(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Btcaddress Suite")
+}
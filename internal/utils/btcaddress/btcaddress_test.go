@@ -0,0 +1,37 @@
+package btcaddress
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/types/btcnetworks"
+)
+
+var _ = Describe("Validate", func() {
+	DescribeTable("valid addresses",
+		func(address string, network btcnetworks.Network) {
+			Expect(Validate(address, network)).To(Succeed())
+		},
+		Entry("P2PKH", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", btcnetworks.Mainnet),
+		Entry("P2SH", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", btcnetworks.Mainnet),
+		Entry("P2WPKH", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", btcnetworks.Mainnet),
+		Entry("P2WPKH uppercase", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", btcnetworks.Mainnet),
+		Entry("P2TR", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", btcnetworks.Mainnet),
+		Entry("testnet P2PKH", "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", btcnetworks.Testnet),
+		Entry("testnet P2WSH", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", btcnetworks.Testnet),
+	)
+
+	DescribeTable("invalid addresses",
+		func(address string, network btcnetworks.Network) {
+			Expect(Validate(address, network)).To(MatchError(ErrInvalid))
+		},
+		Entry("empty", "", btcnetworks.Mainnet),
+		Entry("mainnet address on testnet", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN2", btcnetworks.Testnet),
+		Entry("testnet address on mainnet", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", btcnetworks.Mainnet),
+		Entry("bad base58 checksum", "1BvBMSEYstWetqTFn5Au4m4GFg7xJaNVN3", btcnetworks.Mainnet),
+		Entry("bad bech32 checksum", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", btcnetworks.Mainnet),
+		Entry("mixed case", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sL5k7", btcnetworks.Testnet),
+		Entry("taproot with bech32 checksum", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", btcnetworks.Mainnet),
+		Entry("segwit v0 with bad program length", "BC1QR508D6QEJXTDG4Y5R3ZARVARYV98GJ9P", btcnetworks.Mainnet),
+	)
+})
@@ -1,22 +1,52 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 
+	"github.com/dwarvesf/icy-backend/internal/types/btcnetworks"
 	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
 )
 
 type AppConfig struct {
-	Environment environments.Environment
-	ApiServer   ApiServerConfig
-	Postgres    DBConnection
+	Environment       environments.Environment
+	DeploymentProfile profiles.Profile
+	DryRun            bool
+	ApiServer         ApiServerConfig
+	Postgres          DBConnection
+	Fee               FeeConfig
+	Bitcoin           BitcoinConfig
+	Attestation       AttestationConfig
+	Chaos             ChaosConfig
+	Stream            StreamConfig
+	Health            HealthConfig
+	Oracle            OracleConfig
+	RateLimit         RateLimitConfig
+	ConfigReload      ConfigReloadConfig
+	Log               LogConfig
+	Jobs              JobsConfig
 }
 
 type ApiServerConfig struct {
 	AllowedOrigins string
+	Port           string
+
+	// how long in-flight requests are given to finish on shutdown
+	ShutdownTimeout time.Duration
+
+	// when set, v1 public routes are announced as deprecated in favor of v2
+	V1Sunset time.Time
+
+	// TrustedProxies are the IPs or CIDRs allowed to set X-Forwarded-For, the
+	// client IP used for rate limiting is the remote address when empty
+	TrustedProxies []string
 }
 
 type DBConnection struct {
@@ -27,9 +57,105 @@ type DBConnection struct {
 	Pass string
 
 	SSLMode string
+
+	// QueryTimeout is enforced by postgres as the statement_timeout of every query
+	QueryTimeout time.Duration
+
+	// ReplicaHost of a read replica serving heavy reads, with the same credentials
+	// as the primary. Reads go to the primary when it is empty
+	ReplicaHost string
+	ReplicaPort string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+type BitcoinConfig struct {
+	Network         btcnetworks.Network
+	TreasuryAddress string
+}
+
+type AttestationConfig struct {
+	// hex encoded ed25519 seed used to sign reserve attestations
+	SigningKey string
+}
+
+type ChaosConfig struct {
+	// chaos injection is never enabled in production, regardless of this flag
+	Enabled bool
+}
+
+type StreamConfig struct {
+	PollInterval time.Duration
+}
+
+type HealthConfig struct {
+	ProbeTimeout time.Duration
+	CacheTTL     time.Duration
+}
+
+// RateLimitConfig limits requests per client (API key or IP), cheap public reads
+// and expensive endpoints have separate buckets
+type RateLimitConfig struct {
+	Enabled        bool
+	PublicRPS      float64
+	PublicBurst    int
+	ExpensiveRPS   float64
+	ExpensiveBurst int
+}
+
+// JobConfig schedules a background job, Schedule is a cron expression
+type JobConfig struct {
+	Schedule string
+	Jitter   time.Duration
+}
+
+type JobsConfig struct {
+	OracleRefresh JobConfig
+	RateRecord    JobConfig
+}
+
+// LogConfig overrides the logger defaults of the environment when set
+type LogConfig struct {
+	Level         string
+	Format        string
+	PackageLevels map[string]string
+	Sampling      bool
 }
 
-func New() *AppConfig {
+type ConfigReloadConfig struct {
+	// PollInterval of the config reload, the config is only reloaded on SIGHUP when zero
+	PollInterval time.Duration
+}
+
+type OracleConfig struct {
+	// CacheTTL of the cached oracle values, caching is disabled when zero
+	CacheTTL time.Duration
+}
+
+type FeeConfig struct {
+	Version          string
+	EffectiveFrom    time.Time
+	ServiceFeeTiers  []FeeTier
+	MinSatoshiFee    int64
+	DustThreshold    int64
+	NetworkFeePolicy string
+
+	// sponsorship caps are the max network fee (in satoshi) covered by the service
+	SponsorshipCapPerSwap int64
+	SponsorshipCapDaily   int64
+}
+
+// FeeTier applies FeeBps to swaps with an ICY amount (in wei) >= MinAmount
+type FeeTier struct {
+	MinAmount string
+	FeeBps    int
+}
+
+// New loads the config from the env, it returns every malformed or invalid
+// value at once
+func New() (*AppConfig, error) {
 	env := os.Getenv("APP_ENV")
 	if env == "" {
 		env = "development"
@@ -38,32 +164,236 @@ func New() *AppConfig {
 	// this will load .env file (env from travel-exp repo)
 	// this will not override env variables if they already exist
 	godotenv.Load(".env." + env)
+	if err := loadVaultSecrets(); err != nil {
+		return nil, err
+	}
+
+	return read()
+}
 
-	return &AppConfig{
+// read builds and validates the config from the process env. Vault secrets
+// are only loaded by New, at startup
+func read() (*AppConfig, error) {
+	env := envVarOrDefault("APP_ENV", "development")
+	r := &envReader{}
+
+	cfg := &AppConfig{
+		Environment:       environments.Environment(env),
+		DeploymentProfile: profiles.Profile(envVarOrDefault("DEPLOYMENT_PROFILE", string(profiles.Full))),
+		DryRun:            envVarAsBool("DRY_RUN"),
 		ApiServer: ApiServerConfig{
-			AllowedOrigins: os.Getenv("ALLOWED_ORIGINS"),
+			AllowedOrigins:  os.Getenv("ALLOWED_ORIGINS"),
+			Port:            envVarOrDefault("PORT", "8080"),
+			ShutdownTimeout: time.Duration(r.envVarAtoiOrDefault("SHUTDOWN_TIMEOUT_SECONDS", 30)) * time.Second,
+			V1Sunset:        r.envVarAsTime("API_V1_SUNSET"),
+			TrustedProxies:  envVarAsList("TRUSTED_PROXIES"),
 		},
 		Postgres: DBConnection{
-			Host:    os.Getenv("DB_HOST"),
-			Port:    os.Getenv("DB_PORT"),
-			User:    os.Getenv("DB_USER"),
-			Name:    os.Getenv("DB_NAME"),
-			Pass:    os.Getenv("DB_PASS"),
-			SSLMode: os.Getenv("DB_SSL_MODE"),
+			Host:         os.Getenv("DB_HOST"),
+			Port:         os.Getenv("DB_PORT"),
+			User:         os.Getenv("DB_USER"),
+			Name:         os.Getenv("DB_NAME"),
+			Pass:         os.Getenv("DB_PASS"),
+			SSLMode:      os.Getenv("DB_SSL_MODE"),
+			QueryTimeout: time.Duration(r.envVarAtoiOrDefault("DB_QUERY_TIMEOUT_MS", 5000)) * time.Millisecond,
+
+			ReplicaHost: os.Getenv("DB_REPLICA_HOST"),
+			ReplicaPort: envVarOrDefault("DB_REPLICA_PORT", os.Getenv("DB_PORT")),
+
+			MaxOpenConns:    r.envVarAtoiOrDefault("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    r.envVarAtoiOrDefault("DB_MAX_IDLE_CONNS", 10),
+			ConnMaxLifetime: time.Duration(r.envVarAtoiOrDefault("DB_CONN_MAX_LIFETIME_SECONDS", 1800)) * time.Second,
+		},
+		Fee: FeeConfig{
+			Version:               envVarOrDefault("FEE_SCHEDULE_VERSION", "1"),
+			EffectiveFrom:         r.envVarAsTime("FEE_EFFECTIVE_FROM"),
+			ServiceFeeTiers:       r.parseFeeTiers("FEE_SERVICE_TIERS", "0:100"),
+			MinSatoshiFee:         int64(r.envVarAtoiOrDefault("FEE_MIN_SATOSHI", 1000)),
+			DustThreshold:         int64(r.envVarAtoiOrDefault("FEE_DUST_THRESHOLD", 546)),
+			NetworkFeePolicy:      envVarOrDefault("FEE_NETWORK_POLICY", "deducted"),
+			SponsorshipCapPerSwap: int64(r.envVarAtoiOrDefault("FEE_SPONSORSHIP_CAP_PER_SWAP", 0)),
+			SponsorshipCapDaily:   int64(r.envVarAtoiOrDefault("FEE_SPONSORSHIP_CAP_DAILY", 0)),
+		},
+		Bitcoin: BitcoinConfig{
+			Network:         btcnetworks.Network(envVarOrDefault("BTC_NETWORK", string(btcnetworks.Mainnet))),
+			TreasuryAddress: os.Getenv("BTC_TREASURY_ADDRESS"),
+		},
+		Attestation: AttestationConfig{
+			SigningKey: os.Getenv("ATTESTATION_SIGNING_KEY"),
+		},
+		Chaos: ChaosConfig{
+			Enabled: envVarAsBool("CHAOS_ENABLED"),
+		},
+		Health: HealthConfig{
+			ProbeTimeout: time.Duration(r.envVarAtoiOrDefault("HEALTH_PROBE_TIMEOUT_MS", 800)) * time.Millisecond,
+			CacheTTL:     time.Duration(r.envVarAtoiOrDefault("HEALTH_CACHE_TTL_SECONDS", 5)) * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:        envVarOrDefault("RATE_LIMIT_ENABLED", "true") == "true",
+			PublicRPS:      r.envVarFloatOrDefault("RATE_LIMIT_PUBLIC_RPS", 10),
+			PublicBurst:    r.envVarAtoiOrDefault("RATE_LIMIT_PUBLIC_BURST", 20),
+			ExpensiveRPS:   r.envVarFloatOrDefault("RATE_LIMIT_EXPENSIVE_RPS", 1),
+			ExpensiveBurst: r.envVarAtoiOrDefault("RATE_LIMIT_EXPENSIVE_BURST", 5),
+		},
+		Oracle: OracleConfig{
+			CacheTTL: time.Duration(r.envVarAtoiOrDefault("ORACLE_CACHE_TTL_SECONDS", 300)) * time.Second,
+		},
+		Jobs: JobsConfig{
+			OracleRefresh: JobConfig{
+				Schedule: envVarOrDefault("JOB_ORACLE_REFRESH_SCHEDULE", "* * * * *"),
+				Jitter:   time.Duration(r.envVarAtoiOrDefault("JOB_ORACLE_REFRESH_JITTER_SECONDS", 0)) * time.Second,
+			},
+			RateRecord: JobConfig{
+				Schedule: envVarOrDefault("JOB_RATE_RECORD_SCHEDULE", "* * * * *"),
+				Jitter:   time.Duration(r.envVarAtoiOrDefault("JOB_RATE_RECORD_JITTER_SECONDS", 0)) * time.Second,
+			},
+		},
+		Log: LogConfig{
+			Level:         os.Getenv("LOG_LEVEL"),
+			Format:        os.Getenv("LOG_FORMAT"),
+			PackageLevels: r.parsePackageLevels("LOG_PACKAGE_LEVELS"),
+			Sampling:      envVarAsBool("LOG_SAMPLING"),
 		},
+		ConfigReload: ConfigReloadConfig{
+			PollInterval: time.Duration(r.envVarAtoiOrDefault("CONFIG_RELOAD_INTERVAL_SECONDS", 0)) * time.Second,
+		},
+		Stream: StreamConfig{
+			PollInterval: time.Duration(r.envVarAtoiOrDefault("STREAM_POLL_INTERVAL_SECONDS", 5)) * time.Second,
+		},
+	}
+
+	// the public mirror never holds the signing key, even when it is in the env
+	if cfg.DeploymentProfile == profiles.PublicReadOnly {
+		cfg.Attestation.SigningKey = ""
+	}
+
+	errs := append(r.errs, cfg.Validate())
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return cfg, nil
+}
+
+// envReader parses typed env vars. Malformed values are collected instead of
+// failing on the first one, and read as the default so Validate does not
+// report them twice
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) fail(envName string, err error) {
+	r.errs = append(r.errs, fmt.Errorf("%s: %w", envName, err))
+}
+
+// parseFeeTiers parses tiers in the format "minAmount:feeBps;minAmount:feeBps"
+func (r *envReader) parseFeeTiers(envName, defaultValue string) []FeeTier {
+	tiers := []FeeTier{}
+	for _, item := range strings.Split(envVarOrDefault(envName, defaultValue), ";") {
+		if item == "" {
+			continue
+		}
+		minAmount, feeBpsStr, ok := strings.Cut(item, ":")
+		if !ok {
+			r.fail(envName, fmt.Errorf("invalid fee tier %q", item))
+			continue
+		}
+		feeBps, err := strconv.Atoi(feeBpsStr)
+		if err != nil {
+			r.fail(envName, fmt.Errorf("invalid fee tier %q", item))
+			continue
+		}
+		tiers = append(tiers, FeeTier{MinAmount: minAmount, FeeBps: feeBps})
+	}
+
+	return tiers
+}
+
+// parsePackageLevels parses levels in the format "package=level;package=level"
+func (r *envReader) parsePackageLevels(envName string) map[string]string {
+	levels := map[string]string{}
+	for _, item := range strings.Split(os.Getenv(envName), ";") {
+		if item == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(item, "=")
+		if !ok {
+			r.fail(envName, fmt.Errorf("invalid package log level %q", item))
+			continue
+		}
+		levels[name] = level
 	}
+
+	return levels
 }
 
-func envVarAtoi(envName string) int {
+func (r *envReader) envVarAtoiOrDefault(envName string, defaultValue int) int {
 	valueStr := os.Getenv(envName)
+	if valueStr == "" {
+		return defaultValue
+	}
+
 	value, err := strconv.Atoi(valueStr)
 	if err != nil {
-		panic(err)
+		r.fail(envName, fmt.Errorf("%q is not an integer", valueStr))
+		return defaultValue
+	}
+
+	return value
+}
+
+func (r *envReader) envVarFloatOrDefault(envName string, defaultValue float64) float64 {
+	valueStr := os.Getenv(envName)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		r.fail(envName, fmt.Errorf("%q is not a number", valueStr))
+		return defaultValue
+	}
+
+	return value
+}
+
+func envVarOrDefault(envName string, defaultValue string) string {
+	valueStr := os.Getenv(envName)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	return valueStr
+}
+
+// envVarAsTime parses an RFC3339 timestamp, returning the zero time if unset
+func (r *envReader) envVarAsTime(envName string) time.Time {
+	valueStr := os.Getenv(envName)
+	if valueStr == "" {
+		return time.Time{}
+	}
+
+	value, err := time.Parse(time.RFC3339, valueStr)
+	if err != nil {
+		r.fail(envName, fmt.Errorf("%q is not an RFC3339 timestamp", valueStr))
+		return time.Time{}
 	}
 
 	return value
 }
 
+// envVarAsList splits a comma separated value, returning nil if unset
+func envVarAsList(envName string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(envName), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
 func envVarAsBool(envName string) bool {
 	valueStr := os.Getenv(envName)
 	return valueStr == "true"
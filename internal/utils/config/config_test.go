@@ -1,9 +1,17 @@
 package config
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dwarvesf/icy-backend/internal/types/btcnetworks"
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
 )
 
 var _ = Describe("Config", func() {
@@ -13,4 +21,223 @@ var _ = Describe("Config", func() {
 		})
 
 	})
+
+	Describe("#Watcher", func() {
+		It("should notify subscribers only when the config changed", func() {
+			current := &AppConfig{Oracle: OracleConfig{CacheTTL: time.Minute}}
+			watcher := NewWatcher(current)
+
+			notified := 0
+			watcher.Subscribe(func(old, new *AppConfig) {
+				notified++
+				Expect(old).To(Equal(current))
+				Expect(new.Oracle.CacheTTL).To(Equal(time.Hour))
+			})
+
+			watcher.update(&AppConfig{Oracle: OracleConfig{CacheTTL: time.Minute}})
+			Expect(notified).To(Equal(0))
+
+			watcher.update(&AppConfig{Oracle: OracleConfig{CacheTTL: time.Hour}})
+			Expect(notified).To(Equal(1))
+		})
+	})
+
+	Describe("#reload", func() {
+		It("should keep the secrets read from Vault at startup", func() {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write([]byte(`{"data":{"data":{"DB_PASS":"secret"}}}`))
+			}))
+			defer server.Close()
+			for name, value := range map[string]string{"VAULT_ADDR": server.URL, "DB_HOST": "localhost", "DB_PORT": "5432", "DB_USER": "postgres", "DB_NAME": "icy_backend"} {
+				os.Setenv(name, value)
+				DeferCleanup(os.Unsetenv, name)
+			}
+			DeferCleanup(os.Unsetenv, "DB_PASS")
+
+			_, err := New()
+			Expect(err).NotTo(HaveOccurred())
+
+			cfg, err := reload()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Postgres.Pass).To(Equal("secret"))
+			Expect(requests).To(Equal(1))
+		})
+	})
+
+	Describe("#Validate", func() {
+		var cfg *AppConfig
+
+		BeforeEach(func() {
+			cfg = &AppConfig{
+				Environment:       environments.Test,
+				DeploymentProfile: profiles.Full,
+				ApiServer:         ApiServerConfig{Port: "8080", ShutdownTimeout: time.Second},
+				Postgres:          DBConnection{Host: "localhost", Port: "5432", User: "postgres", Name: "icy", QueryTimeout: time.Second, MaxOpenConns: 1},
+				Bitcoin:           BitcoinConfig{Network: btcnetworks.Mainnet},
+				Fee: FeeConfig{
+					ServiceFeeTiers: []FeeTier{{MinAmount: "0", FeeBps: 100}},
+					MinSatoshiFee:   1000,
+					DustThreshold:   546,
+				},
+				RateLimit: RateLimitConfig{Enabled: true, PublicRPS: 1, PublicBurst: 1, ExpensiveRPS: 1, ExpensiveBurst: 1},
+				Stream:    StreamConfig{PollInterval: time.Second},
+				Health:    HealthConfig{ProbeTimeout: time.Second},
+				Jobs:      JobsConfig{OracleRefresh: JobConfig{Schedule: "* * * * *"}, RateRecord: JobConfig{Schedule: "* * * * *"}},
+			}
+		})
+
+		It("should accept a valid config", func() {
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("should reject a non-positive shutdown timeout", func() {
+			cfg.ApiServer.ShutdownTimeout = 0
+
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring("SHUTDOWN_TIMEOUT_SECONDS")))
+		})
+
+		It("should report the missing database settings in a stable order", func() {
+			cfg.Postgres = DBConnection{QueryTimeout: time.Second, MaxOpenConns: 1}
+
+			Expect(cfg.Validate()).To(MatchError("DB_HOST: is required\nDB_PORT: is required\nDB_USER: is required\nDB_NAME: is required"))
+		})
+
+		It("should reject an unknown environment", func() {
+			cfg.Environment = "prod"
+
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(`APP_ENV: unknown environment "prod"`)))
+		})
+
+		It("should report every problem at once", func() {
+			cfg.Bitcoin.TreasuryAddress = "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7"
+			cfg.Attestation.SigningKey = "not-hex"
+			cfg.RateLimit.PublicRPS = -0.5
+
+			err := cfg.Validate()
+
+			Expect(err).To(MatchError(ContainSubstring("BTC_TREASURY_ADDRESS")))
+			Expect(err).To(MatchError(ContainSubstring("ATTESTATION_SIGNING_KEY")))
+			Expect(err).To(MatchError(ContainSubstring("RATE_LIMIT_")))
+		})
+	})
+
+	Describe("#fetchVaultSecrets", func() {
+		It("should read the KV v2 secret data with the token", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/v1/secret/data/icy-backend"))
+				Expect(r.Header.Get("X-Vault-Token")).To(Equal("token"))
+				w.Write([]byte(`{"data":{"data":{"DB_PASS":"secret"},"metadata":{"version":1}}}`))
+			}))
+			defer server.Close()
+
+			secrets, err := fetchVaultSecrets(server.URL, "token", "secret/data/icy-backend")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secrets).To(Equal(map[string]string{"DB_PASS": "secret"}))
+		})
+
+		It("should fail on a non-200 response", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer server.Close()
+
+			_, err := fetchVaultSecrets(server.URL, "bad", "secret/data/icy-backend")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#New", func() {
+		setenv := func(name, value string) {
+			os.Setenv(name, value)
+			DeferCleanup(os.Unsetenv, name)
+		}
+
+		It("should report malformed and invalid values together", func() {
+			setenv("FEE_MIN_SATOSHI", "abc")
+			setenv("API_V1_SUNSET", "tomorrow")
+			setenv("FEE_SERVICE_TIERS", "100")
+			setenv("LOG_PACKAGE_LEVELS", "oracle")
+			setenv("PORT", "http")
+
+			cfg, err := New()
+
+			Expect(cfg).To(BeNil())
+			Expect(err).To(MatchError(ContainSubstring("FEE_MIN_SATOSHI: \"abc\" is not an integer")))
+			Expect(err).To(MatchError(ContainSubstring("API_V1_SUNSET")))
+			Expect(err).To(MatchError(ContainSubstring(`FEE_SERVICE_TIERS: invalid fee tier "100"`)))
+			Expect(err).To(MatchError(ContainSubstring(`LOG_PACKAGE_LEVELS: invalid package log level "oracle"`)))
+			Expect(err).To(MatchError(ContainSubstring("PORT:")))
+		})
+
+		It("should not load the signing key in the public read-only profile", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"data":{"data":{"DB_PASS":"secret","ATTESTATION_SIGNING_KEY":"key"}}}`))
+			}))
+			defer server.Close()
+			setenv("VAULT_ADDR", server.URL)
+			setenv("DEPLOYMENT_PROFILE", "public-readonly")
+			setenv("DB_HOST", "localhost")
+			setenv("DB_PORT", "5432")
+			setenv("DB_USER", "postgres")
+			setenv("DB_NAME", "icy_backend")
+			DeferCleanup(os.Unsetenv, "DB_PASS")
+			DeferCleanup(os.Unsetenv, "ATTESTATION_SIGNING_KEY")
+
+			cfg, err := New()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Postgres.Pass).To(Equal("secret"))
+			Expect(cfg.Attestation.SigningKey).To(BeEmpty())
+			Expect(os.Getenv("ATTESTATION_SIGNING_KEY")).To(BeEmpty())
+		})
+	})
+
+	Describe("#envReader", func() {
+		var r *envReader
+
+		BeforeEach(func() {
+			r = &envReader{}
+		})
+
+		It("should parse package levels separated by semicolon", func() {
+			os.Setenv("LOG_PACKAGE_LEVELS", "oracle=debug;stream=warn")
+			DeferCleanup(os.Unsetenv, "LOG_PACKAGE_LEVELS")
+
+			Expect(r.parsePackageLevels("LOG_PACKAGE_LEVELS")).To(Equal(map[string]string{"oracle": "debug", "stream": "warn"}))
+			Expect(r.errs).To(BeEmpty())
+		})
+
+		It("should parse fee tiers separated by semicolon", func() {
+			tiers := r.parseFeeTiers("FEE_SERVICE_TIERS", "0:100;1000000000000000000000:50")
+
+			Expect(tiers).To(HaveLen(2))
+			Expect(tiers[0]).To(Equal(FeeTier{MinAmount: "0", FeeBps: 100}))
+			Expect(tiers[1]).To(Equal(FeeTier{MinAmount: "1000000000000000000000", FeeBps: 50}))
+			Expect(r.errs).To(BeEmpty())
+		})
+
+		It("should parse fractional rates", func() {
+			os.Setenv("RATE_LIMIT_PUBLIC_RPS", "0.5")
+			DeferCleanup(os.Unsetenv, "RATE_LIMIT_PUBLIC_RPS")
+
+			Expect(r.envVarFloatOrDefault("RATE_LIMIT_PUBLIC_RPS", 10)).To(Equal(0.5))
+			Expect(r.errs).To(BeEmpty())
+		})
+
+		It("should collect every malformed value and keep the valid ones", func() {
+			os.Setenv("FEE_SERVICE_TIERS", "0:100;100;5:x")
+			DeferCleanup(os.Unsetenv, "FEE_SERVICE_TIERS")
+			os.Setenv("FEE_MIN_SATOSHI", "1k")
+			DeferCleanup(os.Unsetenv, "FEE_MIN_SATOSHI")
+
+			Expect(r.parseFeeTiers("FEE_SERVICE_TIERS", "")).To(Equal([]FeeTier{{MinAmount: "0", FeeBps: 100}}))
+			Expect(r.envVarAtoiOrDefault("FEE_MIN_SATOSHI", 1000)).To(Equal(1000))
+			Expect(r.errs).To(HaveLen(3))
+		})
+	})
 })
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/dwarvesf/icy-backend/internal/types/environments"
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
+	"github.com/dwarvesf/icy-backend/internal/utils/btcaddress"
+	"github.com/dwarvesf/icy-backend/internal/utils/cron"
+)
+
+// Validate returns every problem of the config at once, so a misconfigured
+// deployment is fixed in one go instead of failing mid-request
+func (c *AppConfig) Validate() error {
+	errs := []error{}
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch c.Environment {
+	case environments.Production, environments.Staging, environments.Development, environments.Test:
+	default:
+		add("APP_ENV: unknown environment %q", c.Environment)
+	}
+	switch c.DeploymentProfile {
+	case profiles.Full, profiles.PublicReadOnly:
+	default:
+		add("DEPLOYMENT_PROFILE: unknown profile %q", c.DeploymentProfile)
+	}
+
+	if _, err := strconv.ParseUint(c.ApiServer.Port, 10, 16); err != nil {
+		add("PORT: %q is not a valid port", c.ApiServer.Port)
+	}
+	for _, proxy := range c.ApiServer.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			add("TRUSTED_PROXIES: %q is not an IP or CIDR", proxy)
+		}
+	}
+
+	if c.ApiServer.ShutdownTimeout <= 0 {
+		add("SHUTDOWN_TIMEOUT_SECONDS: must be positive")
+	}
+
+	// a slice rather than a map, so the errors are reported in a stable order
+	for _, required := range []struct{ env, value string }{
+		{"DB_HOST", c.Postgres.Host},
+		{"DB_PORT", c.Postgres.Port},
+		{"DB_USER", c.Postgres.User},
+		{"DB_NAME", c.Postgres.Name},
+	} {
+		if required.value == "" {
+			add("%s: is required", required.env)
+		}
+	}
+
+	if c.Postgres.QueryTimeout <= 0 {
+		add("DB_QUERY_TIMEOUT_MS: must be positive")
+	}
+	if c.Postgres.MaxOpenConns <= 0 || c.Postgres.MaxIdleConns < 0 || c.Postgres.MaxIdleConns > c.Postgres.MaxOpenConns {
+		add("DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS: need 0 <= idle <= open and open > 0")
+	}
+
+	if c.Bitcoin.TreasuryAddress != "" {
+		if err := btcaddress.Validate(c.Bitcoin.TreasuryAddress, c.Bitcoin.Network); err != nil {
+			add("BTC_TREASURY_ADDRESS: %w", err)
+		}
+	}
+
+	if c.Attestation.SigningKey != "" {
+		seed, err := hex.DecodeString(c.Attestation.SigningKey)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			add("ATTESTATION_SIGNING_KEY: expected a hex encoded %d bytes ed25519 seed", ed25519.SeedSize)
+		}
+	}
+
+	if len(c.Fee.ServiceFeeTiers) == 0 {
+		add("FEE_SERVICE_TIERS: at least one tier is required")
+	}
+	for _, tier := range c.Fee.ServiceFeeTiers {
+		if _, ok := new(big.Int).SetString(tier.MinAmount, 10); !ok {
+			add("FEE_SERVICE_TIERS: min amount %q is not an integer", tier.MinAmount)
+		}
+		if tier.FeeBps < 0 || tier.FeeBps > 10000 {
+			add("FEE_SERVICE_TIERS: fee %d bps is out of [0, 10000]", tier.FeeBps)
+		}
+	}
+	if c.Fee.MinSatoshiFee <= 0 {
+		add("FEE_MIN_SATOSHI: must be positive")
+	}
+	if c.Fee.DustThreshold <= 0 {
+		add("FEE_DUST_THRESHOLD: must be positive")
+	}
+	if c.Fee.SponsorshipCapPerSwap < 0 || c.Fee.SponsorshipCapDaily < 0 {
+		add("FEE_SPONSORSHIP_CAP_PER_SWAP, FEE_SPONSORSHIP_CAP_DAILY: must not be negative")
+	}
+
+	if c.Log.Format != "" && c.Log.Format != "json" && c.Log.Format != "console" {
+		add("LOG_FORMAT: expected json or console, got %q", c.Log.Format)
+	}
+	if c.Log.Level != "" {
+		if _, err := zapcore.ParseLevel(c.Log.Level); err != nil {
+			add("LOG_LEVEL: %w", err)
+		}
+	}
+	for name, level := range c.Log.PackageLevels {
+		if _, err := zapcore.ParseLevel(level); err != nil {
+			add("LOG_PACKAGE_LEVELS: %s: %w", name, err)
+		}
+	}
+
+	if _, err := cron.Parse(c.Jobs.OracleRefresh.Schedule); err != nil {
+		add("JOB_ORACLE_REFRESH_SCHEDULE: %w", err)
+	}
+	if _, err := cron.Parse(c.Jobs.RateRecord.Schedule); err != nil {
+		add("JOB_RATE_RECORD_SCHEDULE: %w", err)
+	}
+
+	rl := c.RateLimit
+	// negated so NaN is rejected too
+	if !(rl.PublicRPS > 0) || !(rl.ExpensiveRPS > 0) || math.IsInf(rl.PublicRPS, 1) || math.IsInf(rl.ExpensiveRPS, 1) {
+		add("RATE_LIMIT_PUBLIC_RPS, RATE_LIMIT_EXPENSIVE_RPS: must be positive, e.g 0.5 for one request every 2 seconds")
+	}
+	if rl.PublicBurst <= 0 || rl.ExpensiveBurst <= 0 {
+		add("RATE_LIMIT_PUBLIC_BURST, RATE_LIMIT_EXPENSIVE_BURST: must be positive")
+	}
+	if c.Oracle.CacheTTL < 0 {
+		add("ORACLE_CACHE_TTL_SECONDS: must not be negative")
+	}
+	if c.Stream.PollInterval <= 0 {
+		add("STREAM_POLL_INTERVAL_SECONDS: must be positive")
+	}
+	if c.Health.ProbeTimeout <= 0 {
+		add("HEALTH_PROBE_TIMEOUT_MS: must be positive")
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dwarvesf/icy-backend/internal/types/profiles"
+)
+
+// secretEnvVars are the env vars which can be resolved from Vault instead of
+// being set in plaintext
+var secretEnvVars = []string{"DB_PASS", "ATTESTATION_SIGNING_KEY"}
+
+// loadVaultSecrets sets the secret env vars which are not already set from the
+// Vault KV v2 secret at VAULT_SECRET_PATH, it does nothing when VAULT_ADDR is
+// unset. The public read-only profile never loads the attestation signing key
+func loadVaultSecrets() error {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil
+	}
+
+	secrets, err := fetchVaultSecrets(addr, os.Getenv("VAULT_TOKEN"), envVarOrDefault("VAULT_SECRET_PATH", "secret/data/icy-backend"))
+	if err != nil {
+		return err
+	}
+
+	readOnly := profiles.Profile(os.Getenv("DEPLOYMENT_PROFILE")) == profiles.PublicReadOnly
+	for _, name := range secretEnvVars {
+		if readOnly && name == "ATTESTATION_SIGNING_KEY" {
+			continue
+		}
+		if value, ok := secrets[name]; ok && os.Getenv(name) == "" {
+			os.Setenv(name, value)
+		}
+	}
+
+	return nil
+}
+
+func fetchVaultSecrets(addr, token, path string) (map[string]string, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: unexpected status %d reading %s", resp.StatusCode, path)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+
+	return body.Data.Data, nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// ChangeFunc is called with the previous and the reloaded config
+type ChangeFunc func(old, new *AppConfig)
+
+// Watcher reloads the config and notifies subscribers of changes. Only the
+// subsystems which subscribe pick up new values, everything else keeps the
+// config it was started with
+type Watcher struct {
+	mux         *sync.Mutex
+	current     *AppConfig
+	subscribers []ChangeFunc
+}
+
+func NewWatcher(current *AppConfig) *Watcher {
+	return &Watcher{
+		mux:     &sync.Mutex{},
+		current: current,
+	}
+}
+
+func (w *Watcher) Subscribe(fn ChangeFunc) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload re-reads the env file, letting it override the process env, and
+// notifies the subscribers when the config changed. An invalid config is
+// rejected and the current one is kept
+func (w *Watcher) Reload() error {
+	// reload fails on an invalid config, New validates it
+	next, err := reload()
+	if err != nil {
+		return err
+	}
+
+	w.update(next)
+	return nil
+}
+
+func (w *Watcher) update(next *AppConfig) {
+	w.mux.Lock()
+	old := w.current
+	if reflect.DeepEqual(old, next) {
+		w.mux.Unlock()
+		return
+	}
+	w.current = next
+	subscribers := append([]ChangeFunc{}, w.subscribers...)
+	w.mux.Unlock()
+
+	for _, fn := range subscribers {
+		fn(old, next)
+	}
+}
+
+func reload() (*AppConfig, error) {
+	env := envVarOrDefault("APP_ENV", "development")
+	if err := godotenv.Overload(".env." + env); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	// secrets are read from Vault at startup only, rotating them needs a restart
+	return read()
+}
@@ -0,0 +1,43 @@
+package cron
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	at := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	DescribeTable("#Next",
+		func(expr, from, expected string) {
+			schedule, err := Parse(expr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.Next(at(from))).To(Equal(at(expected)))
+		},
+		Entry("every minute", "* * * * *", "2024-01-01T10:00:30Z", "2024-01-01T10:01:00Z"),
+		Entry("every 5 minutes", "*/5 * * * *", "2024-01-01T10:01:00Z", "2024-01-01T10:05:00Z"),
+		Entry("hourly across the day", "@hourly", "2024-01-01T23:30:00Z", "2024-01-02T00:00:00Z"),
+		Entry("weekdays at 9", "0 9 * * 1-5", "2024-01-05T10:00:00Z", "2024-01-08T09:00:00Z"),
+		Entry("lists", "15,45 * * * *", "2024-01-01T10:20:00Z", "2024-01-01T10:45:00Z"),
+		Entry("day of month or day of week", "0 0 1 * 0", "2024-01-02T00:00:00Z", "2024-01-07T00:00:00Z"),
+		Entry("leap day", "0 0 29 2 *", "2024-03-01T00:00:00Z", "2028-02-29T00:00:00Z"),
+	)
+
+	DescribeTable("invalid expressions",
+		func(expr string) {
+			_, err := Parse(expr)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("out of range", "60 * * * *"),
+		Entry("inverted range", "* 10-5 * * *"),
+		Entry("zero step", "*/0 * * * *"),
+		Entry("not a number", "a * * * *"),
+	)
+})
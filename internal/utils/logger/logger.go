@@ -1,16 +1,42 @@
 package logger
 
 import (
+	"context"
+
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/dwarvesf/icy-backend/internal/types/environments"
 )
 
+// Options overrides the defaults of the environment, zero values keep them
+type Options struct {
+	// Level is the minimum level, e.g debug, info, warn or error
+	Level string
+	// Format is json or console
+	Format string
+	// PackageLevels sets the level of the loggers created with Named
+	PackageLevels map[string]string
+	// Sampling keeps the first 100 entries with the same message per second,
+	// then every 100th, for high-volume paths
+	Sampling bool
+}
+
 type Logger struct {
 	wrappedLogger *zap.Logger
+
+	// base logs at every enabled level of any package, named loggers derive
+	// from it and apply their own level
+	base          *zap.Logger
+	level         zapcore.Level
+	packageLevels map[string]zapcore.Level
 }
 
 func New(env environments.Environment) *Logger {
+	return NewWithOptions(env, Options{})
+}
+
+func NewWithOptions(env environments.Environment, opts Options) *Logger {
 	var cfg zap.Config
 
 	switch env {
@@ -26,14 +52,91 @@ func New(env environments.Environment) *Logger {
 		cfg = newProductionLoggerConfig()
 	}
 
+	level := cfg.Level.Level()
+	if opts.Level != "" {
+		level = mustParseLevel(opts.Level)
+	}
+	if opts.Format != "" {
+		cfg.Encoding = opts.Format
+	}
+	if opts.Sampling {
+		cfg.Sampling = &zap.SamplingConfig{Initial: 100, Thereafter: 100}
+	}
+
+	minLevel := level
+	packageLevels := map[string]zapcore.Level{}
+	for name, lvl := range opts.PackageLevels {
+		packageLevels[name] = mustParseLevel(lvl)
+		minLevel = min(minLevel, packageLevels[name])
+	}
+	cfg.Level = zap.NewAtomicLevelAt(minLevel)
+
 	zapLogger, err := cfg.Build()
 	if err != nil {
 		panic(err)
 	}
 
 	return &Logger{
-		wrappedLogger: zapLogger,
+		wrappedLogger: withLevel(zapLogger, level),
+		base:          zapLogger,
+		level:         level,
+		packageLevels: packageLevels,
+	}
+}
+
+// Named returns a logger for a package, using its level from Options.PackageLevels
+func (l *Logger) Named(name string) *Logger {
+	level, ok := l.packageLevels[name]
+	if !ok {
+		level = l.level
+	}
+
+	base := l.base.Named(name)
+	return &Logger{
+		wrappedLogger: withLevel(base, level),
+		base:          base,
+		level:         l.level,
+		packageLevels: l.packageLevels,
+	}
+}
+
+// WithFields returns a logger adding fields to every entry
+func (l *Logger) WithFields(fields map[string]any) *Logger {
+	zapFields := transformAnyMapToFields(fields)
+	return &Logger{
+		wrappedLogger: l.wrappedLogger.With(zapFields...),
+		base:          l.base.With(zapFields...),
+		level:         l.level,
+		packageLevels: l.packageLevels,
+	}
+}
+
+type contextKey struct{}
+
+// ContextWithFields returns a context carrying fields for WithContext, on top
+// of the fields already in ctx
+func ContextWithFields(ctx context.Context, fields map[string]any) context.Context {
+	merged := map[string]any{}
+	if existing, ok := ctx.Value(contextKey{}).(map[string]any); ok {
+		for k, v := range existing {
+			merged[k] = v
+		}
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, contextKey{}, merged)
+}
+
+// WithContext returns a logger adding the fields set by ContextWithFields
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	fields, ok := ctx.Value(contextKey{}).(map[string]any)
+	if !ok {
+		return l
 	}
+
+	return l.WithFields(fields)
 }
 
 func (l *Logger) Debug(msg string, inputFields ...map[string]string) {
@@ -84,3 +187,33 @@ func transformStrMapToFields(strMap map[string]string) []zap.Field {
 
 	return fields
 }
+
+func transformAnyMapToFields(anyMap map[string]any) []zap.Field {
+	fields := []zap.Field{}
+	for k, v := range anyMap {
+		fields = append(fields, zap.Any(k, v))
+	}
+
+	return fields
+}
+
+// withLevel raises the level of l, entries below it are dropped
+func withLevel(l *zap.Logger, level zapcore.Level) *zap.Logger {
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		leveled, err := zapcore.NewIncreaseLevelCore(core, level)
+		if err != nil {
+			// level is below the core level, which already filters more
+			return core
+		}
+		return leveled
+	}))
+}
+
+func mustParseLevel(level string) zapcore.Level {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		panic(err)
+	}
+
+	return lvl
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"bytes"
+	"context"
 	"sort"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -62,6 +63,43 @@ var _ = Describe("Logger", func() {
 		})
 	})
 
+	Describe("#NewWithOptions", func() {
+		It("should override the level of the environment", func() {
+			logger = NewWithOptions(environments.Production, Options{Level: "warn"})
+
+			Expect(logger.wrappedLogger.Core().Enabled(zapcore.InfoLevel)).To(BeFalse())
+			Expect(logger.wrappedLogger.Core().Enabled(zapcore.WarnLevel)).To(BeTrue())
+		})
+
+		It("should panic on an unknown level", func() {
+			Expect(func() { NewWithOptions(environments.Test, Options{Level: "loud"}) }).To(Panic())
+		})
+	})
+
+	Describe("#Named", func() {
+		It("should apply the level of the package", func() {
+			logger = NewWithOptions(environments.Production, Options{PackageLevels: map[string]string{"oracle": "debug"}})
+
+			Expect(logger.wrappedLogger.Core().Enabled(zapcore.DebugLevel)).To(BeFalse())
+			Expect(logger.Named("oracle").wrappedLogger.Core().Enabled(zapcore.DebugLevel)).To(BeTrue())
+			Expect(logger.Named("stream").wrappedLogger.Core().Enabled(zapcore.DebugLevel)).To(BeFalse())
+		})
+	})
+
+	Describe("#WithContext", func() {
+		It("should return the same logger without context fields", func() {
+			logger = New(environments.Test)
+			Expect(logger.WithContext(context.Background())).To(BeIdenticalTo(logger))
+		})
+
+		It("should merge the fields of nested contexts", func() {
+			ctx := ContextWithFields(context.Background(), map[string]any{"a": 1})
+			ctx = ContextWithFields(ctx, map[string]any{"b": 2})
+
+			Expect(ctx.Value(contextKey{})).To(Equal(map[string]any{"a": 1, "b": 2}))
+		})
+	})
+
 	Describe("#Debug", func() {
 		BeforeEach(func() {
 			logger = New(environments.Test)
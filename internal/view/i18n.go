@@ -0,0 +1,88 @@
+package view
+
+import "golang.org/x/text/language"
+
+// ErrorCode is the machine-readable code of a user-facing error, it never changes
+// with the language of the message
+type ErrorCode string
+
+const (
+	ErrCodeCirculatedICYUnavailable       ErrorCode = "circulated_icy_unavailable"
+	ErrCodeTreasuryBTCUnavailable         ErrorCode = "treasury_btc_unavailable"
+	ErrCodeCachedCirculatedICYUnavailable ErrorCode = "cached_circulated_icy_unavailable"
+	ErrCodeCachedTreasuryBTCUnavailable   ErrorCode = "cached_treasury_btc_unavailable"
+	ErrCodeRealtimePriceUnavailable       ErrorCode = "realtime_icy_btc_price_unavailable"
+	ErrCodeCachedPriceUnavailable         ErrorCode = "cached_icy_btc_price_unavailable"
+	ErrCodeAttestationUnavailable         ErrorCode = "reserve_attestation_unavailable"
+	ErrCodeInvalidChaosFault              ErrorCode = "invalid_chaos_fault"
+	ErrCodeChaosTargetUnknown             ErrorCode = "chaos_target_unknown"
+	ErrCodeRateLimited                    ErrorCode = "rate_limited"
+	ErrCodeUnauthorized                   ErrorCode = "unauthorized"
+	ErrCodeForbidden                      ErrorCode = "forbidden"
+	ErrCodeInvalidRateHistoryQuery        ErrorCode = "invalid_rate_history_query"
+	ErrCodeRateHistoryUnavailable         ErrorCode = "rate_history_unavailable"
+	ErrCodeJobNotFound                    ErrorCode = "job_not_found"
+	ErrCodeJobRunning                     ErrorCode = "job_running"
+)
+
+var supportedLanguages = []language.Tag{
+	language.English, // default
+	language.Vietnamese,
+}
+
+var languageMatcher = language.NewMatcher(supportedLanguages)
+
+var messageCatalog = map[language.Tag]map[ErrorCode]string{
+	language.English: {
+		ErrCodeCirculatedICYUnavailable:       "can't get circulated ICY",
+		ErrCodeTreasuryBTCUnavailable:         "can't get treasury BTC",
+		ErrCodeCachedCirculatedICYUnavailable: "can't get cached circulated ICY",
+		ErrCodeCachedTreasuryBTCUnavailable:   "can't get cached treasury BTC",
+		ErrCodeRealtimePriceUnavailable:       "can't get realtime ICY/BTC price",
+		ErrCodeCachedPriceUnavailable:         "can't get cached ICY/BTC price",
+		ErrCodeAttestationUnavailable:         "can't attest reserves",
+		ErrCodeInvalidChaosFault:              "invalid fault",
+		ErrCodeChaosTargetUnknown:             "unknown chaos target",
+		ErrCodeRateLimited:                    "too many requests, please retry later",
+		ErrCodeUnauthorized:                   "missing or invalid API key",
+		ErrCodeForbidden:                      "API key is not allowed to access this resource",
+		ErrCodeInvalidRateHistoryQuery:        "invalid from, to or interval",
+		ErrCodeRateHistoryUnavailable:         "can't get ICY/BTC price history",
+		ErrCodeJobNotFound:                    "job not found",
+		ErrCodeJobRunning:                     "job is already running",
+	},
+	language.Vietnamese: {
+		ErrCodeCirculatedICYUnavailable:       "không thể lấy lượng ICY đang lưu hành",
+		ErrCodeTreasuryBTCUnavailable:         "không thể lấy số dư BTC của quỹ",
+		ErrCodeCachedCirculatedICYUnavailable: "không thể lấy lượng ICY đang lưu hành đã lưu",
+		ErrCodeCachedTreasuryBTCUnavailable:   "không thể lấy số dư BTC của quỹ đã lưu",
+		ErrCodeRealtimePriceUnavailable:       "không thể lấy giá ICY/BTC theo thời gian thực",
+		ErrCodeCachedPriceUnavailable:         "không thể lấy giá ICY/BTC đã lưu",
+		ErrCodeAttestationUnavailable:         "không thể tạo chứng thực dự trữ",
+		ErrCodeInvalidChaosFault:              "cấu hình lỗi không hợp lệ",
+		ErrCodeChaosTargetUnknown:             "không tìm thấy đối tượng giả lập lỗi",
+		ErrCodeRateLimited:                    "quá nhiều yêu cầu, vui lòng thử lại sau",
+		ErrCodeUnauthorized:                   "API key không hợp lệ hoặc bị thiếu",
+		ErrCodeForbidden:                      "API key không có quyền truy cập tài nguyên này",
+		ErrCodeInvalidRateHistoryQuery:        "from, to hoặc interval không hợp lệ",
+		ErrCodeRateHistoryUnavailable:         "không thể lấy lịch sử giá ICY/BTC",
+		ErrCodeJobNotFound:                    "không tìm thấy job",
+		ErrCodeJobRunning:                     "job đang chạy",
+	},
+}
+
+// LocalizeMessage returns the message of code in the best language matching the
+// Accept-Language header, falling back to English then to the code itself
+func LocalizeMessage(code ErrorCode, acceptLanguage string) string {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := languageMatcher.Match(tags...)
+
+	if msg, ok := messageCatalog[supportedLanguages[index]][code]; ok {
+		return msg
+	}
+	if msg, ok := messageCatalog[language.English][code]; ok {
+		return msg
+	}
+
+	return string(code)
+}
@@ -0,0 +1,38 @@
+package view
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("I18n", func() {
+	Describe("#LocalizeMessage", func() {
+		It("should default to English when Accept-Language is empty", func() {
+			Expect(LocalizeMessage(ErrCodeTreasuryBTCUnavailable, "")).To(Equal("can't get treasury BTC"))
+		})
+
+		It("should pick Vietnamese when it is preferred", func() {
+			Expect(LocalizeMessage(ErrCodeTreasuryBTCUnavailable, "vi-VN,vi;q=0.9,en;q=0.8")).To(Equal("không thể lấy số dư BTC của quỹ"))
+		})
+
+		It("should fall back to English for unsupported languages", func() {
+			Expect(LocalizeMessage(ErrCodeTreasuryBTCUnavailable, "fr-FR")).To(Equal("can't get treasury BTC"))
+		})
+
+		It("should fall back to the code for unknown codes", func() {
+			Expect(LocalizeMessage(ErrorCode("unknown_code"), "vi")).To(Equal("unknown_code"))
+		})
+	})
+
+	Describe("#CreateErrorResponse", func() {
+		It("should keep the code regardless of the language", func() {
+			resp := CreateErrorResponse(errors.New("rpc down"), nil, ErrCodeTreasuryBTCUnavailable, "vi")
+
+			Expect(resp.Code).To(Equal("treasury_btc_unavailable"))
+			Expect(resp.Error).To(Equal("rpc down"))
+			Expect(resp.Message).To(Equal("không thể lấy số dư BTC của quỹ"))
+		})
+	})
+})
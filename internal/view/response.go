@@ -19,13 +19,25 @@ type ApiError struct {
 type Response[T any] struct {
 	Data         T          `json:"data"`
 	Message      string     `json:"message,omitempty"`
+	Code         string     `json:"code,omitempty"`
 	Error        string     `json:"error,omitempty"`
 	ErrorDetails []ApiError `json:"errors,omitempty"`
 }
 
+// DataResponse documents the envelope of CreateResponse, annotate handlers
+// with DataResponse{data=model.X}
+type DataResponse struct {
+	Data    any    `json:"data"`
+	Message string `json:"message,omitempty"`
+} // @name DataResponse
+
+// ErrorResponse documents the body of CreateErrorResponse
 type ErrorResponse struct {
-	Error        string     `json:"error"`
-	ErrorDetails []ApiError `json:"errors"`
+	Data         any        `json:"data"`
+	Message      string     `json:"message"`
+	Code         string     `json:"code"`
+	Error        string     `json:"error,omitempty"`
+	ErrorDetails []ApiError `json:"errors,omitempty"`
 } // @name ErrorResponse
 
 type MessageResponse struct {
@@ -79,3 +91,12 @@ func CreateResponse[T any](data T, err error, payload any, message string) Respo
 
 	return resp
 }
+
+// CreateErrorResponse creates an error response with a machine-readable code and
+// the message localized according to the Accept-Language header
+func CreateErrorResponse(err error, payload any, code ErrorCode, acceptLanguage string) Response[any] {
+	resp := CreateResponse[any](nil, err, payload, LocalizeMessage(code, acceptLanguage))
+	resp.Code = string(code)
+
+	return resp
+}